@@ -3,7 +3,11 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"shien/internal/config"
 	"shien/internal/daemon"
 	"shien/internal/paths"
 )
@@ -11,6 +15,10 @@ import (
 func main() {
 	// Parse command-line flags
 	dataDir := flag.String("data-dir", "", "Custom data directory (default: ~/.config/shien)")
+	
+	// Every setting can be given as a flag, e.g. -idle-threshold 10m
+	configFlags := config.Overrides{}
+	configFlags.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
 	// Set custom data directory if provided
@@ -23,7 +31,7 @@ func main() {
 
 	log.Println("Starting shien-service daemon...")
 
-	d := daemon.New()
+	d := daemon.New(configFlags)
 	
 	// Start daemon in a goroutine
 	go func() {
@@ -32,6 +40,16 @@ func main() {
 		}
 	}()
 
-	// Start system tray (this will block)
+	// Stop gracefully on interrupt or termination
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %s, shutting down", sig)
+		d.Stop()
+	}()
+
+	// Start system tray (this will block until the daemon stops)
 	d.StartTray()
+	log.Println("shien-service stopped")
 }
\ No newline at end of file
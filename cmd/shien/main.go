@@ -44,6 +44,12 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
 	}
+	defer client.Close()
+
+	// A client token gives the calls its scope
+	if token := os.Getenv("SHIEN_TOKEN"); token != "" {
+		client.SetToken(token)
+	}
 
 	// Initialize command registry
 	registry := commands.NewRegistry()
@@ -84,9 +90,21 @@ func registerCommands(registry *commands.Registry) {
 	registry.Register(commands.NewStatusCommand())
 	registry.Register(commands.NewActivityCommand())
 	registry.Register(commands.NewWeeklyCommand())
+	registry.Register(commands.NewSessionsCommand())
+	registry.Register(commands.NewProjectCommand())
+	registry.Register(commands.NewLogCommand())
+	registry.Register(commands.NewAppsCommand())
+	registry.Register(commands.NewPrivacyCommand())
+	registry.Register(commands.NewPauseCommand())
+	registry.Register(commands.NewResumeCommand())
 	registry.Register(commands.NewConfigCommand())
+	registry.Register(commands.NewStopCommand())
 	registry.Register(commands.NewPingCommand())
 	registry.Register(commands.NewGameCommand())
+	registry.Register(commands.NewWatchCommand())
+	registry.Register(commands.NewMethodsCommand())
+	registry.Register(commands.NewDashboardCommand())
+	registry.Register(commands.NewTokenCommand())
 }
 
 func printUsage() {
@@ -104,7 +122,7 @@ func printUsage() {
 	
 	// Display each command with its description
 	commandList := registry.List()
-	for _, cmd := range []string{"status", "activity", "weekly", "game", "config", "ping"} { // Maintain order
+	for _, cmd := range []string{"status", "pause", "resume", "activity", "weekly", "sessions", "project", "log", "apps", "privacy", "game", "watch", "dashboard", "config", "methods", "token", "stop", "ping"} { // Maintain order
 		if command, exists := commandList[cmd]; exists {
 			fmt.Printf("  %-20s %s\n", command.Name(), command.Description())
 			if command.Usage() != command.Name() {
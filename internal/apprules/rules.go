@@ -0,0 +1,156 @@
+package apprules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"shien/internal/utils"
+)
+
+// Match types
+const (
+	MatchExact = "exact" // The raw name equals the pattern
+	MatchGlob  = "glob"  // Shell pattern such as "JetBrains *" (case-insensitive)
+	MatchRegex = "regex" // Go regular expression
+)
+
+// Rule maps raw app names matching a pattern to a category
+type Rule struct {
+	Match    string `json:"match"`
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
+}
+
+// String describes the rule for display
+func (r Rule) String() string {
+	return fmt.Sprintf("%s %q", r.Match, r.Pattern)
+}
+
+// Sources of the rule that matched an app name
+const (
+	SourceFile    = "file"    // A rule in the rules file
+	SourceBuiltin = "builtin" // The built-in mapping
+	SourceNone    = "none"    // No rule matched; the raw name is used
+)
+
+// Result is the outcome of normalizing an app name
+type Result struct {
+	Category string `json:"category"`
+	Source   string `json:"source"`
+	Rule     *Rule  `json:"rule,omitempty"`
+}
+
+// compiledRule is a rule ready for matching
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// matches reports whether the rule applies to a raw app name
+func (r compiledRule) matches(raw string) bool {
+	switch r.Match {
+	case MatchExact:
+		return raw == r.Pattern
+	case MatchGlob:
+		matched, _ := path.Match(strings.ToLower(r.Pattern), strings.ToLower(raw))
+		return matched
+	case MatchRegex:
+		return r.re.MatchString(raw)
+	}
+	return false
+}
+
+// RuleSet normalizes app names with user rules checked in order before the
+// built-in mapping
+type RuleSet struct {
+	rules   []compiledRule
+	builtin map[string]string
+}
+
+// NewRuleSet compiles user rules on top of the built-in mapping
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	return &RuleSet{rules: compiled, builtin: utils.DefaultAppNames()}, nil
+}
+
+// compile validates a rule and compiles its pattern
+func compile(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+
+	if rule.Pattern == "" {
+		return c, fmt.Errorf("pattern is empty")
+	}
+	if strings.TrimSpace(rule.Category) == "" {
+		return c, fmt.Errorf("category is empty")
+	}
+
+	switch rule.Match {
+	case MatchExact:
+	case MatchGlob:
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return c, fmt.Errorf("invalid glob %q: %w", rule.Pattern, err)
+		}
+	case MatchRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return c, fmt.Errorf("invalid regex %q: %w", rule.Pattern, err)
+		}
+		c.re = re
+	default:
+		return c, fmt.Errorf("unknown match type %q (want %s, %s or %s)", rule.Match, MatchExact, MatchGlob, MatchRegex)
+	}
+
+	return c, nil
+}
+
+// Normalize maps a raw app name to its category
+func (s *RuleSet) Normalize(raw string) Result {
+	for i := range s.rules {
+		if s.rules[i].matches(raw) {
+			rule := s.rules[i].Rule
+			return Result{Category: rule.Category, Source: SourceFile, Rule: &rule}
+		}
+	}
+
+	if category, ok := s.builtin[raw]; ok {
+		return Result{
+			Category: category,
+			Source:   SourceBuiltin,
+			Rule:     &Rule{Match: MatchExact, Pattern: raw, Category: category},
+		}
+	}
+
+	return Result{Category: raw, Source: SourceNone}
+}
+
+// Rules returns the user rules in match order
+func (s *RuleSet) Rules() []Rule {
+	rules := make([]Rule, len(s.rules))
+	for i, rule := range s.rules {
+		rules[i] = rule.Rule
+	}
+	return rules
+}
+
+// BuiltinRules returns the built-in mapping as exact rules sorted by name
+func (s *RuleSet) BuiltinRules() []Rule {
+	rules := make([]Rule, 0, len(s.builtin))
+	for raw, category := range s.builtin {
+		rules = append(rules, Rule{Match: MatchExact, Pattern: raw, Category: category})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Pattern < rules[j].Pattern
+	})
+	return rules
+}
@@ -0,0 +1,114 @@
+package apprules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"shien/internal/filewatch"
+)
+
+// File is the on-disk format of the rules file
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Store holds the current rule set loaded from the rules file
+type Store struct {
+	path    string
+	rules   *RuleSet
+	loadErr error // Error from the last load, if it failed
+	mu      sync.RWMutex
+}
+
+// NewStore creates a store for the rules file at path using only the
+// built-in mapping until Load is called
+func NewStore(path string) *Store {
+	rules, _ := NewRuleSet(nil)
+	return &Store{path: path, rules: rules}
+}
+
+// Path returns the rules file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the rules file. A missing file is created with no rules.
+// If the file is invalid, the previous rules are kept and the error returned.
+func (s *Store) Load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		if err := s.writeEmpty(); err != nil {
+			return err
+		}
+		data = []byte(`{"rules": []}`)
+	} else if err != nil {
+		return s.setError(err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return s.setError(fmt.Errorf("failed to parse %s: %w", s.path, err))
+	}
+
+	rules, err := NewRuleSet(file.Rules)
+	if err != nil {
+		return s.setError(fmt.Errorf("invalid rule in %s: %w", s.path, err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	s.loadErr = nil
+	return nil
+}
+
+// Watch reloads the rules whenever the file changes, until ctx is canceled
+func (s *Store) Watch(ctx context.Context) {
+	filewatch.Watch(ctx, s.path, filewatch.DefaultInterval, func() {
+		if err := s.Load(); err != nil {
+			log.Printf("Keeping previous app rules: %v", err)
+			return
+		}
+		log.Printf("Reloaded app rules from %s", s.path)
+	})
+}
+
+// Rules returns the current rule set
+func (s *Store) Rules() *RuleSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules
+}
+
+// LoadError returns the error from the last load, or nil if it succeeded
+func (s *Store) LoadError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadErr
+}
+
+// Normalize maps a raw app name to its category with the current rules
+func (s *Store) Normalize(raw string) Result {
+	return s.Rules().Normalize(raw)
+}
+
+// setError records a failed load and returns err
+func (s *Store) setError(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadErr = err
+	return err
+}
+
+// writeEmpty creates the rules file with an empty rule list
+func (s *Store) writeEmpty() error {
+	data, err := json.MarshalIndent(File{Rules: []Rule{}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcomes of audited calls
+const (
+	OutcomeOK     = "ok"     // The call succeeded
+	OutcomeDenied = "denied" // The client may not call the method
+	OutcomeFailed = "failed" // The method returned an error
+)
+
+// AuditEntry records an administrative call
+type AuditEntry struct {
+	Time      time.Time              `json:"time"`
+	Method    string                 `json:"method"`
+	Transport string                 `json:"transport"`       // "socket" or "http"
+	UID       *int                   `json:"uid,omitempty"`   // UID of the client process, if known
+	Token     string                 `json:"token,omitempty"` // Name of the token the client used
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Outcome   string                 `json:"outcome"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// AuditLog appends entries to a file as JSON lines
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog creates an audit log writing to the file at path
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends an entry. The file is created so only the user can read it.
+func (l *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
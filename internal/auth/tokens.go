@@ -0,0 +1,223 @@
+// Package auth holds the tokens RPC clients authenticate with, the scopes
+// that decide which methods they may call, and the audit log of
+// administrative calls.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope decides which methods a client may call
+type Scope string
+
+// Scopes
+const (
+	ScopeNone  Scope = "none"  // Nothing but authenticating
+	ScopeRead  Scope = "read"  // Methods that change nothing
+	ScopeAdmin Scope = "admin" // Every method, e.g. shutdown and update_config
+)
+
+// ParseScope checks the name of a scope a token can have
+func ParseScope(name string) (Scope, error) {
+	switch scope := Scope(name); scope {
+	case ScopeRead, ScopeAdmin:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("scope must be %q or %q, got %q", ScopeRead, ScopeAdmin, name)
+	}
+}
+
+// Allows reports whether the scope may call a method, given whether the
+// method changes nothing
+func (s Scope) Allows(readOnly bool) bool {
+	switch s {
+	case ScopeAdmin:
+		return true
+	case ScopeRead:
+		return readOnly
+	default:
+		return false
+	}
+}
+
+var (
+	// ErrTokenExists is returned when creating a token with a name in use
+	ErrTokenExists = errors.New("a token with this name already exists")
+
+	// ErrUnknownToken is returned when revoking a token that doesn't exist
+	ErrUnknownToken = errors.New("no token with this name")
+)
+
+// Token is a client token. Only a hash of its secret is kept; the secret is
+// shown once, when the token is created.
+type Token struct {
+	Name      string    `json:"name"`
+	Scope     Scope     `json:"scope"`
+	CreatedAt time.Time `json:"created_at"`
+	Hash      string    `json:"hash,omitempty"` // SHA-256 of the secret, hex-encoded
+}
+
+// Store keeps the client tokens in a file only the user can read
+type Store struct {
+	path   string
+	tokens []Token
+	mu     sync.RWMutex
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the tokens from the file. A missing file means no tokens.
+func (s *Store) Load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var tokens []Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return fmt.Errorf("invalid tokens file %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = tokens
+	return nil
+}
+
+// Create adds a token and returns its secret
+func (s *Store) Create(name string, scope Scope) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("token name is required")
+	}
+	if _, err := ParseScope(string(scope)); err != nil {
+		return "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	secret := hex.EncodeToString(random)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range s.tokens {
+		if token.Name == name {
+			return "", fmt.Errorf("%w: %s", ErrTokenExists, name)
+		}
+	}
+
+	tokens := append(append([]Token{}, s.tokens...), Token{
+		Name:      name,
+		Scope:     scope,
+		CreatedAt: time.Now().UTC(),
+		Hash:      hash(secret),
+	})
+	if err := s.save(tokens); err != nil {
+		return "", err
+	}
+	s.tokens = tokens
+	return secret, nil
+}
+
+// Revoke removes a token
+func (s *Store) Revoke(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := make([]Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		if token.Name != name {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == len(s.tokens) {
+		return fmt.Errorf("%w: %s", ErrUnknownToken, name)
+	}
+
+	if err := s.save(tokens); err != nil {
+		return err
+	}
+	s.tokens = tokens
+	return nil
+}
+
+// List returns the tokens by name, without their hashes
+func (s *Store) List() []Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]Token, len(s.tokens))
+	for i, token := range s.tokens {
+		token.Hash = ""
+		tokens[i] = token
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens
+}
+
+// Lookup returns the token with the given secret
+func (s *Store) Lookup(secret string) (Token, bool) {
+	digest := []byte(hash(secret))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare(digest, []byte(token.Hash)) == 1 {
+			token.Hash = ""
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// save writes the tokens to the file, replacing it in one step
+func (s *Store) save(tokens []Token) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".tokens-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// CreateTemp makes files only the user can read
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// hash returns the hex-encoded SHA-256 of a secret
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"shien/internal/cli/display"
+	"shien/internal/rpc"
+	"shien/internal/service"
+)
+
+// AppsCommand shows raw app names and the normalization rules matching them
+type AppsCommand struct{}
+
+// NewAppsCommand creates a new apps command
+func NewAppsCommand() *AppsCommand {
+	return &AppsCommand{}
+}
+
+// Name returns the command name
+func (c *AppsCommand) Name() string {
+	return "apps"
+}
+
+// Description returns the command description
+func (c *AppsCommand) Description() string {
+	return "List apps seen and the category rule matching each"
+}
+
+// Usage returns the command usage
+func (c *AppsCommand) Usage() string {
+	return `apps [options]
+    -days <n>         Number of days to include (default 7)
+  apps recategorize -from <date> [-to <date>] [-dry-run]
+                      Re-apply the current rules to past activity`
+}
+
+// Execute runs the apps command
+func (c *AppsCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) > 0 && args[0] == "recategorize" {
+		return c.recategorize(client, args[1:])
+	}
+
+	flags := flag.NewFlagSet("apps", flag.ExitOnError)
+	days := flags.Int("days", 7, "Number of days to include")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	now := time.Now()
+	params := map[string]interface{}{
+		"from": now.AddDate(0, 0, -*days).Format(time.RFC3339),
+		"to":   now.Format(time.RFC3339),
+	}
+
+	var report service.AppsReport
+	if err := callInto(client, rpc.MethodGetApps, params, &report); err != nil {
+		return fmt.Errorf("failed to get apps: %w", err)
+	}
+
+	display.NewAppsReporter().ShowApps(&report)
+	return nil
+}
+
+// recategorize re-applies the current rules over a date range
+func (c *AppsCommand) recategorize(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("apps recategorize", flag.ExitOnError)
+	from := flags.String("from", "", "Start date (YYYY-MM-DD)")
+	to := flags.String("to", "", "End date (YYYY-MM-DD), default now")
+	dryRun := flags.Bool("dry-run", false, "Only report what would change")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *from == "" {
+		return fmt.Errorf("-from is required")
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid from date: %w", err)
+	}
+	params := map[string]interface{}{
+		"from":    start.Format(time.RFC3339),
+		"dry_run": *dryRun,
+	}
+
+	if *to != "" {
+		end, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid to date: %w", err)
+		}
+		// Set to end of day
+		end = end.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		params["to"] = end.Format(time.RFC3339)
+	}
+
+	var result service.RecategorizeResult
+	if err := callInto(client, rpc.MethodRecategorizeApps, params, &result); err != nil {
+		return fmt.Errorf("failed to recategorize: %w", err)
+	}
+
+	display.NewAppsReporter().ShowRecategorize(&result)
+	return nil
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"shien/internal/rpc"
+)
+
+// callInto calls an RPC method and decodes the response data into out (if non-nil)
+func callInto(client *rpc.Client, method string, params map[string]interface{}, out interface{}) error {
+	resp, err := client.Call(method, params)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("error: %s", resp.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	// Convert response data to the expected type
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// parseLocalTime parses "YYYY-MM-DD HH:MM", "YYYY-MM-DDTHH:MM", "YYYY-MM-DD"
+// or "HH:MM" (today) in local time
+func parseLocalTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (want YYYY-MM-DD HH:MM, YYYY-MM-DD or HH:MM)", value)
+}
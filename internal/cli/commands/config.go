@@ -2,12 +2,17 @@ package commands
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 
+	"shien/internal/cli/display"
+	"shien/internal/config"
+	"shien/internal/paths"
 	"shien/internal/rpc"
+	"shien/internal/service"
 )
 
-// ConfigCommand handles configuration display
+// ConfigCommand shows and updates configuration
 type ConfigCommand struct{}
 
 // NewConfigCommand creates a new config command
@@ -22,34 +27,136 @@ func (c *ConfigCommand) Name() string {
 
 // Description returns the command description
 func (c *ConfigCommand) Description() string {
-	return "Show current configuration"
+	return "Show or update configuration"
 }
 
 // Usage returns the command usage
 func (c *ConfigCommand) Usage() string {
-	return "config"
+	return `config [-json]         Show current configuration and where each value comes from
+  config set <key> <value>
+                         Update a setting (value is JSON, or a plain string)
+  config describe        List every setting with its value and where it comes from
+  config validate [file] Check a config file (default: the daemon's config.json)`
 }
 
 // Execute runs the config command
 func (c *ConfigCommand) Execute(client *rpc.Client, args []string) error {
-	resp, err := client.Call(rpc.MethodGetConfig, nil)
-	if err != nil {
+	if len(args) > 0 {
+		switch args[0] {
+		case "set":
+			return c.set(client, args[1:])
+		case "describe":
+			return c.describe(client)
+		case "validate":
+			return c.validate(args[1:])
+		}
+	}
+
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "Print the effective configuration as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *jsonOutput {
+		resp, err := client.Call(rpc.MethodGetConfig, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		if !resp.Success {
+			return fmt.Errorf("error: %s", resp.Error)
+		}
+
+		// Pretty print config
+		data, err := json.MarshalIndent(resp.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format config: %w", err)
+		}
+
+		fmt.Println(string(data))
+		return nil
+	}
+
+	var description service.ConfigDescription
+	if err := callInto(client, rpc.MethodDescribeConfig, nil, &description); err != nil {
 		return fmt.Errorf("failed to get config: %w", err)
 	}
 
-	if !resp.Success {
-		return fmt.Errorf("error: %s", resp.Error)
+	display.NewConfigReporter().ShowSettings(&description)
+	return nil
+}
+
+// set updates a single setting
+func (c *ConfigCommand) set(client *rpc.Client, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: shien config set <key> <value>")
 	}
+	key := args[0]
 
-	// Pretty print config
-	data, err := json.MarshalIndent(resp.Data, "", "  ")
+	value, err := config.ParseText(key, args[1])
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"updates": map[string]interface{}{key: value},
+	}
+
+	var result rpc.ConfigUpdate
+	if err := callInto(client, rpc.MethodUpdateConfig, params, &result); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+
+	if len(result.Overridden) > 0 {
+		option, _ := config.LookupOption(key)
+		fmt.Printf("Saved %s, but it is overridden by %s or -%s\n", key, option.EnvVar(), option.Flag())
+		return nil
+	}
+	if len(result.Changed) == 0 {
+		fmt.Printf("%s is unchanged\n", key)
+		return nil
+	}
+
+	value, _ = result.Config.Value(key)
+	updated, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("failed to format config: %w", err)
 	}
+	fmt.Printf("Set %s = %s\n", key, updated)
+	return nil
+}
 
-	fmt.Println("Current Configuration")
-	fmt.Println("====================")
-	fmt.Println(string(data))
+// describe lists every setting with its value and source
+func (c *ConfigCommand) describe(client *rpc.Client) error {
+	var description service.ConfigDescription
+	if err := callInto(client, rpc.MethodDescribeConfig, nil, &description); err != nil {
+		return fmt.Errorf("failed to describe config: %w", err)
+	}
 
+	display.NewConfigReporter().ShowDescription(&description)
 	return nil
-}
\ No newline at end of file
+}
+
+// validate checks a config file locally, so it works without the daemon
+func (c *ConfigCommand) validate(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: shien config validate [file]")
+	}
+	path := paths.ConfigFile()
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	warnings, err := config.ValidateFile(path)
+	for _, warning := range warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if err != nil {
+		return fmt.Errorf("%s is invalid:\n%w", path, err)
+	}
+
+	fmt.Printf("✅ %s is valid\n", path)
+	return nil
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"shien/internal/config"
+	"shien/internal/paths"
+	"shien/internal/rpc"
+)
+
+// DashboardCommand opens the web dashboard served by the daemon
+type DashboardCommand struct{}
+
+// NewDashboardCommand creates a new dashboard command
+func NewDashboardCommand() *DashboardCommand {
+	return &DashboardCommand{}
+}
+
+// Name returns the command name
+func (c *DashboardCommand) Name() string {
+	return "dashboard"
+}
+
+// Description returns the command description
+func (c *DashboardCommand) Description() string {
+	return "Open the web dashboard (needs http_port)"
+}
+
+// Usage returns the command usage
+func (c *DashboardCommand) Usage() string {
+	return `dashboard [-print]`
+}
+
+// Execute runs the dashboard command
+func (c *DashboardCommand) Execute(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	printOnly := flags.Bool("print", false, "Print the URL instead of opening a browser")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	var cfg config.Config
+	if err := callInto(client, rpc.MethodGetConfig, nil, &cfg); err != nil {
+		return fmt.Errorf("failed to get config: %w", err)
+	}
+	if cfg.HTTPPort == 0 {
+		return fmt.Errorf("the HTTP gateway is disabled; enable it with: shien config set http_port 8787")
+	}
+
+	// The daemon writes the token when the gateway starts
+	token, err := os.ReadFile(paths.HTTPTokenFile())
+	if err != nil {
+		return fmt.Errorf("failed to read gateway token: %w", err)
+	}
+
+	// The token goes in the fragment, which the browser doesn't send
+	url := fmt.Sprintf("http://127.0.0.1:%d/#token=%s", cfg.HTTPPort, strings.TrimSpace(string(token)))
+	if *printOnly {
+		fmt.Println(url)
+		return nil
+	}
+
+	if err := openBrowser(url); err != nil {
+		return fmt.Errorf("failed to open browser: %w (run with -print for the URL)", err)
+	}
+	fmt.Printf("Opened the dashboard at http://127.0.0.1:%d/\n", cfg.HTTPPort)
+	return nil
+}
+
+// openBrowser opens a URL in the default browser
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/rpc"
+)
+
+// LogCommand records work done away from the computer
+type LogCommand struct{}
+
+// NewLogCommand creates a new log command
+func NewLogCommand() *LogCommand {
+	return &LogCommand{}
+}
+
+// Name returns the command name
+func (c *LogCommand) Name() string {
+	return "log"
+}
+
+// Description returns the command description
+func (c *LogCommand) Description() string {
+	return "Log offline work (meetings, whiteboarding, reading)"
+}
+
+// Usage returns the command usage
+func (c *LogCommand) Usage() string {
+	return `log [options]
+    -category <name>  Meeting, Whiteboarding, Reading or any other category
+    -start <time>     Start time (HH:MM or YYYY-MM-DD HH:MM)
+    -end <time>       End time (HH:MM or YYYY-MM-DD HH:MM)
+    -duration <d>     Duration instead of -end (e.g. 45m, 1h30m)
+    -note <text>      Optional note
+  log list [-date <date>]  Show manual entries (default today)`
+}
+
+// Execute runs the log command
+func (c *LogCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		return c.list(client, args[1:])
+	}
+
+	flags := flag.NewFlagSet("log", flag.ExitOnError)
+	category := flags.String("category", "", "Activity category")
+	start := flags.String("start", "", "Start time")
+	end := flags.String("end", "", "End time")
+	duration := flags.Duration("duration", 0, "Duration instead of -end")
+	note := flags.String("note", "", "Optional note")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *category == "" || *start == "" {
+		return fmt.Errorf("-category and -start are required\nUsage: %s", c.Usage())
+	}
+	if (*end == "") == (*duration == 0) {
+		return fmt.Errorf("specify exactly one of -end and -duration")
+	}
+
+	startTime, err := parseLocalTime(*start)
+	if err != nil {
+		return err
+	}
+
+	endTime := startTime.Add(*duration)
+	if *end != "" {
+		if endTime, err = parseLocalTime(*end); err != nil {
+			return err
+		}
+	}
+
+	var entry repository.ManualEntry
+	err = callInto(client, rpc.MethodAddManualEntry, map[string]interface{}{
+		"start":    startTime.Format(time.RFC3339),
+		"end":      endTime.Format(time.RFC3339),
+		"category": *category,
+		"note":     *note,
+	}, &entry)
+	if err != nil {
+		return fmt.Errorf("failed to log entry: %w", err)
+	}
+
+	fmt.Printf("Logged %s %s - %s (%s)\n", entry.Category,
+		entry.StartedAt.Format("2006-01-02 15:04"), entry.EndedAt.Format("15:04"),
+		entry.Duration().Round(time.Minute))
+	return nil
+}
+
+// list shows the manual entries of a day
+func (c *LogCommand) list(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("log list", flag.ExitOnError)
+	date := flags.String("date", "", "Date (YYYY-MM-DD)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	day := time.Now()
+	if *date != "" {
+		t, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date: %w", err)
+		}
+		day = t
+	}
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+	var entries []repository.ManualEntry
+	err := callInto(client, rpc.MethodGetManualEntries, map[string]interface{}{
+		"from": from.Format(time.RFC3339),
+		"to":   from.AddDate(0, 0, 1).Format(time.RFC3339),
+	}, &entries)
+	if err != nil {
+		return fmt.Errorf("failed to get manual entries: %w", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No manual entries found for", from.Format("2006-01-02"))
+		return nil
+	}
+
+	fmt.Println("Manual Entries")
+	fmt.Println("==============")
+	for _, entry := range entries {
+		fmt.Printf("  %s - %s  %7s  %-16s %s\n",
+			entry.StartedAt.Format("15:04"), entry.EndedAt.Format("15:04"),
+			entry.Duration().Round(time.Minute), entry.Category, entry.Note)
+	}
+
+	return nil
+}
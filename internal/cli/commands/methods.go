@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"shien/internal/cli/display"
+	"shien/internal/rpc"
+	"shien/internal/rpc/registry"
+)
+
+// MethodsCommand describes the RPC methods served by the daemon
+type MethodsCommand struct{}
+
+// NewMethodsCommand creates a new methods command
+func NewMethodsCommand() *MethodsCommand {
+	return &MethodsCommand{}
+}
+
+// Name returns the command name
+func (c *MethodsCommand) Name() string {
+	return "methods"
+}
+
+// Description returns the command description
+func (c *MethodsCommand) Description() string {
+	return "List the RPC methods of the daemon and their params"
+}
+
+// Usage returns the command usage
+func (c *MethodsCommand) Usage() string {
+	return `methods [<method>]`
+}
+
+// Execute runs the methods command
+func (c *MethodsCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: %s", c.Usage())
+	}
+
+	params := map[string]interface{}{}
+	if len(args) == 1 {
+		params["method"] = args[0]
+	}
+
+	var methods []registry.Method
+	if err := client.Invoke(rpc.MethodDiscover, params, &methods); err != nil {
+		return fmt.Errorf("failed to describe methods: %w", err)
+	}
+
+	reporter := display.NewMethodsReporter()
+	if len(args) == 1 {
+		reporter.ShowMethod(methods[0])
+		return nil
+	}
+	reporter.ShowMethods(methods)
+	return nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"shien/internal/rpc"
+	"shien/internal/service"
+)
+
+// PauseCommand pauses activity tracking
+type PauseCommand struct{}
+
+// NewPauseCommand creates a new pause command
+func NewPauseCommand() *PauseCommand {
+	return &PauseCommand{}
+}
+
+// Name returns the command name
+func (c *PauseCommand) Name() string {
+	return "pause"
+}
+
+// Description returns the command description
+func (c *PauseCommand) Description() string {
+	return "Pause activity tracking"
+}
+
+// Usage returns the command usage
+func (c *PauseCommand) Usage() string {
+	return `pause [duration]    Pause for a duration (e.g. 30m, 2h) or until resumed`
+}
+
+// Execute runs the pause command
+func (c *PauseCommand) Execute(client *rpc.Client, args []string) error {
+	params := make(map[string]interface{})
+	if len(args) > 0 {
+		duration, err := time.ParseDuration(args[0])
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("invalid duration: %s", args[0])
+		}
+		if duration < service.MinPauseDuration {
+			return fmt.Errorf("invalid duration: %s (pause for at least a minute)", args[0])
+		}
+		params["duration"] = duration.String()
+	}
+
+	var state service.PauseState
+	if err := callInto(client, rpc.MethodPause, params, &state); err != nil {
+		return fmt.Errorf("failed to pause: %w", err)
+	}
+
+	if state.Until != nil {
+		fmt.Printf("Tracking paused until %s\n", state.Until.Format("15:04"))
+	} else {
+		fmt.Println("Tracking paused until resumed (shien resume)")
+	}
+	return nil
+}
+
+// ResumeCommand resumes activity tracking
+type ResumeCommand struct{}
+
+// NewResumeCommand creates a new resume command
+func NewResumeCommand() *ResumeCommand {
+	return &ResumeCommand{}
+}
+
+// Name returns the command name
+func (c *ResumeCommand) Name() string {
+	return "resume"
+}
+
+// Description returns the command description
+func (c *ResumeCommand) Description() string {
+	return "Resume activity tracking"
+}
+
+// Usage returns the command usage
+func (c *ResumeCommand) Usage() string {
+	return "resume"
+}
+
+// Execute runs the resume command
+func (c *ResumeCommand) Execute(client *rpc.Client, args []string) error {
+	if err := callInto(client, rpc.MethodResume, nil, nil); err != nil {
+		return fmt.Errorf("failed to resume: %w", err)
+	}
+
+	fmt.Println("Tracking resumed")
+	return nil
+}
@@ -32,10 +32,16 @@ func (c *PingCommand) Usage() string {
 
 // Execute runs the ping command
 func (c *PingCommand) Execute(client *rpc.Client, args []string) error {
-	if err := client.Ping(); err != nil {
+	resp, err := client.Call(rpc.MethodPing, nil)
+	if err != nil {
 		fmt.Println("❌ Daemon is not running")
 		os.Exit(1)
 	}
+	if !resp.Success {
+		// E.g. the client's scope doesn't allow it
+		fmt.Printf("❌ Daemon is running but refused the ping: %s\n", resp.Error)
+		os.Exit(1)
+	}
 
 	fmt.Println("✅ Daemon is running")
 	return nil
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"shien/internal/privacy"
+	"shien/internal/rpc"
+	"shien/internal/service"
+)
+
+// PrivacyCommand checks how the privacy rules handle windows
+type PrivacyCommand struct{}
+
+// NewPrivacyCommand creates a new privacy command
+func NewPrivacyCommand() *PrivacyCommand {
+	return &PrivacyCommand{}
+}
+
+// Name returns the command name
+func (c *PrivacyCommand) Name() string {
+	return "privacy"
+}
+
+// Description returns the command description
+func (c *PrivacyCommand) Description() string {
+	return "Test how privacy rules handle a window"
+}
+
+// Usage returns the command usage
+func (c *PrivacyCommand) Usage() string {
+	return `privacy test -app <name> [-title <title>] [-url <url>]`
+}
+
+// Execute runs the privacy command
+func (c *PrivacyCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) == 0 || args[0] != "test" {
+		return fmt.Errorf("usage: %s", c.Usage())
+	}
+
+	flags := flag.NewFlagSet("privacy test", flag.ExitOnError)
+	app := flags.String("app", "", "Raw app name as reported by the OS")
+	title := flags.String("title", "", "Window title")
+	url := flags.String("url", "", "Browser URL")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *app == "" {
+		return fmt.Errorf("-app is required")
+	}
+
+	var preview service.PrivacyPreview
+	err := callInto(client, rpc.MethodTestPrivacy, map[string]interface{}{
+		"app":   *app,
+		"title": *title,
+		"url":   *url,
+	}, &preview)
+	if err != nil {
+		return fmt.Errorf("failed to test privacy rules: %w", err)
+	}
+
+	fmt.Println("Privacy Test")
+	fmt.Println("============")
+	fmt.Printf("App:   %s\n", *app)
+	if *title != "" {
+		fmt.Printf("Title: %s\n", *title)
+	}
+	fmt.Println()
+
+	if preview.Rule != nil {
+		fmt.Printf("Matched rule: %s\n", preview.Rule)
+	} else if preview.Action == privacy.ActionRecord {
+		fmt.Println("No rule matched")
+	} else {
+		fmt.Println("Privacy rules are invalid; all window details are redacted until they are fixed")
+	}
+
+	switch preview.Action {
+	case privacy.ActionDrop:
+		fmt.Println("Result: the sample is dropped and nothing is recorded")
+	case privacy.ActionPrivate:
+		fmt.Printf("Result: recorded as %q without any window details\n", preview.Category)
+	case privacy.ActionRedact:
+		fmt.Printf("Result: recorded as %q with the title, document and URL removed\n", preview.Category)
+	default:
+		fmt.Printf("Result: recorded as %q with the title\n", preview.Category)
+	}
+
+	return nil
+}
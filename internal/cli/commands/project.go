@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"shien/internal/cli/display"
+	"shien/internal/database/repository"
+	"shien/internal/rpc"
+	"shien/internal/service"
+)
+
+// ProjectCommand manages projects, project rules and tags
+type ProjectCommand struct{}
+
+// NewProjectCommand creates a new project command
+func NewProjectCommand() *ProjectCommand {
+	return &ProjectCommand{}
+}
+
+// Name returns the command name
+func (c *ProjectCommand) Name() string {
+	return "project"
+}
+
+// Description returns the command description
+func (c *ProjectCommand) Description() string {
+	return "Manage projects and attribute time to them"
+}
+
+// Usage returns the command usage
+func (c *ProjectCommand) Usage() string {
+	return `project <subcommand> [options]
+    create <name> [-description <text>]
+    list                                   List projects
+    rules [list]                           List assignment rules
+    rules add <project> -kind <app|title|time> -pattern <pattern> [-priority <n>]
+    rules remove <rule-id>
+    assign <project> -from <time> -to <time>   Re-assign a range ("" clears)
+    tag <tag> -from <time> -to <time>          Tag a range
+    report [-from <date>] [-to <date>]         Time per project and tag (default today)`
+}
+
+// Execute runs the project command
+func (c *ProjectCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand\nUsage: %s", c.Usage())
+	}
+
+	switch args[0] {
+	case "create":
+		return c.create(client, args[1:])
+	case "list":
+		return c.list(client)
+	case "rules":
+		return c.rules(client, args[1:])
+	case "assign":
+		return c.assign(client, args[1:])
+	case "tag":
+		return c.tag(client, args[1:])
+	case "report":
+		return c.report(client, args[1:])
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nUsage: %s", args[0], c.Usage())
+	}
+}
+
+func (c *ProjectCommand) create(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("project name is required")
+	}
+
+	flags := flag.NewFlagSet("project create", flag.ExitOnError)
+	description := flags.String("description", "", "Project description")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	var project repository.Project
+	err := callInto(client, rpc.MethodCreateProject, map[string]interface{}{
+		"name":        args[0],
+		"description": *description,
+	}, &project)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	fmt.Printf("Created project %q (id %d)\n", project.Name, project.ID)
+	return nil
+}
+
+func (c *ProjectCommand) list(client *rpc.Client) error {
+	var projects []repository.Project
+	if err := callInto(client, rpc.MethodGetProjects, nil, &projects); err != nil {
+		return fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	display.NewProjectReporter().ShowProjects(projects)
+	return nil
+}
+
+func (c *ProjectCommand) rules(client *rpc.Client, args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		var rules []repository.ProjectRule
+		if err := callInto(client, rpc.MethodGetProjectRules, nil, &rules); err != nil {
+			return fmt.Errorf("failed to get rules: %w", err)
+		}
+
+		display.NewProjectReporter().ShowRules(rules)
+		return nil
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf("project name is required")
+		}
+
+		flags := flag.NewFlagSet("project rules add", flag.ExitOnError)
+		kind := flags.String("kind", "app", "Rule kind: app, title or time")
+		pattern := flags.String("pattern", "", "App name, title regex or HH:MM-HH:MM")
+		priority := flags.Int("priority", 0, "Higher priorities are checked first")
+		if err := flags.Parse(args[2:]); err != nil {
+			return fmt.Errorf("failed to parse flags: %w", err)
+		}
+
+		var rule repository.ProjectRule
+		err := callInto(client, rpc.MethodAddProjectRule, map[string]interface{}{
+			"project":  args[1],
+			"kind":     *kind,
+			"pattern":  *pattern,
+			"priority": *priority,
+		}, &rule)
+		if err != nil {
+			return fmt.Errorf("failed to add rule: %w", err)
+		}
+
+		fmt.Printf("Added rule %d: %s %q -> %s\n", rule.ID, rule.Kind, rule.Pattern, rule.ProjectName)
+		return nil
+
+	case "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("rule id is required")
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule id: %s", args[1])
+		}
+
+		if err := callInto(client, rpc.MethodRemoveProjectRule, map[string]interface{}{"id": id}, nil); err != nil {
+			return fmt.Errorf("failed to remove rule: %w", err)
+		}
+
+		fmt.Printf("Removed rule %d\n", id)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown rules subcommand: %s", args[0])
+	}
+}
+
+func (c *ProjectCommand) assign(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("project name is required")
+	}
+
+	params, err := c.rangeParams("project assign", args[1:])
+	if err != nil {
+		return err
+	}
+	params["project"] = args[0]
+
+	var result struct {
+		Updated int64 `json:"updated"`
+	}
+	if err := callInto(client, rpc.MethodAssignProject, params, &result); err != nil {
+		return fmt.Errorf("failed to assign project: %w", err)
+	}
+
+	if args[0] == "" {
+		fmt.Printf("Cleared the project of %d records\n", result.Updated)
+	} else {
+		fmt.Printf("Assigned %d records to %s\n", result.Updated, args[0])
+	}
+	return nil
+}
+
+func (c *ProjectCommand) tag(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("tag is required")
+	}
+
+	params, err := c.rangeParams("project tag", args[1:])
+	if err != nil {
+		return err
+	}
+	params["tag"] = args[0]
+
+	var result struct {
+		Updated int64 `json:"updated"`
+	}
+	if err := callInto(client, rpc.MethodTagActivity, params, &result); err != nil {
+		return fmt.Errorf("failed to tag activity: %w", err)
+	}
+
+	fmt.Printf("Tagged %d records with %s\n", result.Updated, args[0])
+	return nil
+}
+
+func (c *ProjectCommand) report(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("project report", flag.ExitOnError)
+	from := flags.String("from", "", "Start date (YYYY-MM-DD)")
+	to := flags.String("to", "", "End date (YYYY-MM-DD)")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	params := make(map[string]interface{})
+	if *from != "" {
+		t, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid from date: %w", err)
+		}
+		params["from"] = t.Format(time.RFC3339)
+	}
+	if *to != "" {
+		t, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid to date: %w", err)
+		}
+		// Set to end of day
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		params["to"] = t.Format(time.RFC3339)
+	}
+
+	var report service.ProjectReport
+	if err := callInto(client, rpc.MethodGetProjectReport, params, &report); err != nil {
+		return fmt.Errorf("failed to get project report: %w", err)
+	}
+
+	display.NewProjectReporter().ShowReport(&report)
+	return nil
+}
+
+// rangeParams parses the mandatory -from and -to flags
+func (c *ProjectCommand) rangeParams(name string, args []string) (map[string]interface{}, error) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	from := flags.String("from", "", "Start time (YYYY-MM-DD HH:MM or HH:MM)")
+	to := flags.String("to", "", "End time (YYYY-MM-DD HH:MM or HH:MM)")
+	if err := flags.Parse(args); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *from == "" || *to == "" {
+		return nil, fmt.Errorf("-from and -to are required")
+	}
+
+	start, err := parseLocalTime(*from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseLocalTime(*to)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"from": start.Format(time.RFC3339),
+		"to":   end.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"shien/internal/cli/display"
+	"shien/internal/database/repository"
+	"shien/internal/rpc"
+)
+
+// SessionsCommand handles the work session timeline
+type SessionsCommand struct{}
+
+// NewSessionsCommand creates a new sessions command
+func NewSessionsCommand() *SessionsCommand {
+	return &SessionsCommand{}
+}
+
+// Name returns the command name
+func (c *SessionsCommand) Name() string {
+	return "sessions"
+}
+
+// Description returns the command description
+func (c *SessionsCommand) Description() string {
+	return "Show work sessions as a timeline"
+}
+
+// Usage returns the command usage
+func (c *SessionsCommand) Usage() string {
+	return `sessions [options]
+    -from <date>      Start date (YYYY-MM-DD)
+    -to <date>        End date (YYYY-MM-DD)
+    -today            Show today's sessions (default)`
+}
+
+// Execute runs the sessions command
+func (c *SessionsCommand) Execute(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("sessions", flag.ExitOnError)
+	from := flags.String("from", "", "Start date (YYYY-MM-DD)")
+	to := flags.String("to", "", "End date (YYYY-MM-DD)")
+	flags.Bool("today", false, "Show today's sessions (default)")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	// Without a range the daemon returns today's sessions
+	params := make(map[string]interface{})
+
+	if *from != "" {
+		t, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid from date: %w", err)
+		}
+		params["from"] = t.Format(time.RFC3339)
+	}
+
+	if *to != "" {
+		t, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid to date: %w", err)
+		}
+		// Set to end of day
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		params["to"] = t.Format(time.RFC3339)
+	}
+
+	resp, err := client.Call(rpc.MethodGetSessions, params)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("error: %s", resp.Error)
+	}
+
+	// Convert response data to sessions
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var sessions []repository.Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return fmt.Errorf("failed to parse sessions: %w", err)
+	}
+
+	// Display the timeline
+	reporter := display.NewSessionReporter()
+	reporter.ShowTimeline(sessions)
+
+	return nil
+}
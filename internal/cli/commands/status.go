@@ -43,6 +43,18 @@ func (c *StatusCommand) Execute(client *rpc.Client, args []string) error {
 	fmt.Printf("Started: %s\n", status.StartedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Uptime:  %s\n", time.Since(status.StartedAt).Round(time.Second))
 	fmt.Printf("Version: %s\n", status.Version)
+	fmt.Printf("Tracking: %s\n", formatTrackingState(status))
 
 	return nil
-}
\ No newline at end of file
+}
+
+// formatTrackingState describes whether tracking is paused
+func formatTrackingState(status *rpc.Status) string {
+	if !status.Paused {
+		return "active"
+	}
+	if status.PausedUntil != nil {
+		return "paused until " + status.PausedUntil.Format("2006-01-02 15:04")
+	}
+	return "paused until resumed"
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"shien/internal/paths"
+	"shien/internal/rpc"
+)
+
+// StopCommand stops the daemon
+type StopCommand struct{}
+
+// NewStopCommand creates a new stop command
+func NewStopCommand() *StopCommand {
+	return &StopCommand{}
+}
+
+// Name returns the command name
+func (c *StopCommand) Name() string {
+	return "stop"
+}
+
+// Description returns the command description
+func (c *StopCommand) Description() string {
+	return "Stop the daemon"
+}
+
+// Usage returns the command usage
+func (c *StopCommand) Usage() string {
+	return "stop"
+}
+
+// Execute runs the stop command
+func (c *StopCommand) Execute(client *rpc.Client, args []string) error {
+	if err := callInto(client, rpc.MethodShutdown, nil, nil); err != nil {
+		return fmt.Errorf("failed to stop daemon: %w", err)
+	}
+
+	// The daemon removes its socket once it has stopped
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(paths.SocketFile()); os.IsNotExist(err) {
+			fmt.Println("Daemon stopped")
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	fmt.Println("Shutdown requested; the daemon is still stopping")
+	return nil
+}
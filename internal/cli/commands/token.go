@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"shien/internal/auth"
+	"shien/internal/cli/display"
+	"shien/internal/rpc"
+)
+
+// TokenCommand manages the client tokens of the daemon
+type TokenCommand struct{}
+
+// NewTokenCommand creates a new token command
+func NewTokenCommand() *TokenCommand {
+	return &TokenCommand{}
+}
+
+// Name returns the command name
+func (c *TokenCommand) Name() string {
+	return "token"
+}
+
+// Description returns the command description
+func (c *TokenCommand) Description() string {
+	return "Manage client tokens (use one with SHIEN_TOKEN)"
+}
+
+// Usage returns the command usage
+func (c *TokenCommand) Usage() string {
+	return `token <subcommand>
+    create <name> [-scope read|admin]      Create a token and print its secret (default scope read)
+    list                                   List tokens
+    revoke <name>                          Revoke a token`
+}
+
+// Execute runs the token command
+func (c *TokenCommand) Execute(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand\nUsage: %s", c.Usage())
+	}
+
+	switch args[0] {
+	case "create":
+		return c.create(client, args[1:])
+	case "list":
+		return c.list(client)
+	case "revoke":
+		return c.revoke(client, args[1:])
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nUsage: %s", args[0], c.Usage())
+	}
+}
+
+func (c *TokenCommand) create(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("token name is required")
+	}
+
+	flags := flag.NewFlagSet("token create", flag.ExitOnError)
+	scope := flags.String("scope", string(auth.ScopeRead), "Scope of the token: read or admin")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	var token rpc.CreatedToken
+	err := callInto(client, rpc.MethodCreateToken, map[string]interface{}{
+		"name":  args[0],
+		"scope": *scope,
+	}, &token)
+	if err != nil {
+		return fmt.Errorf("failed to create token: %w", err)
+	}
+
+	fmt.Printf("Created %s token %q. Its secret is not shown again:\n\n", token.Scope, token.Name)
+	fmt.Printf("  %s\n\n", token.Secret)
+	fmt.Println("Use it with: SHIEN_TOKEN=<secret> shien <command>")
+	return nil
+}
+
+func (c *TokenCommand) list(client *rpc.Client) error {
+	var tokens []auth.Token
+	if err := callInto(client, rpc.MethodListTokens, nil, &tokens); err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	display.NewTokenReporter().ShowTokens(tokens)
+	return nil
+}
+
+func (c *TokenCommand) revoke(client *rpc.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("token name is required")
+	}
+
+	if err := callInto(client, rpc.MethodRevokeToken, map[string]interface{}{"name": args[0]}, nil); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	fmt.Printf("Revoked token %q\n", args[0])
+	return nil
+}
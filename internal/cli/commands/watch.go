@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"shien/internal/cli/display"
+	"shien/internal/events"
+	"shien/internal/rpc"
+)
+
+// WatchCommand streams daemon events as they happen
+type WatchCommand struct{}
+
+// NewWatchCommand creates a new watch command
+func NewWatchCommand() *WatchCommand {
+	return &WatchCommand{}
+}
+
+// Name returns the command name
+func (c *WatchCommand) Name() string {
+	return "watch"
+}
+
+// Description returns the command description
+func (c *WatchCommand) Description() string {
+	return "Stream activity, level ups, config and pause changes live"
+}
+
+// Usage returns the command usage
+func (c *WatchCommand) Usage() string {
+	return `watch [options]
+    -topics <list>    Comma-separated topics or prefixes (default all), e.g. gamification,config
+    -json             Print one JSON event per line`
+}
+
+// Execute runs the watch command
+func (c *WatchCommand) Execute(client *rpc.Client, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	topicList := flags.String("topics", "", "Comma-separated topics or prefixes (default all)")
+	jsonOutput := flags.Bool("json", false, "Print one JSON event per line")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	var topics []string
+	for _, topic := range strings.Split(*topicList, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	reporter := display.NewEventReporter()
+	encoder := json.NewEncoder(os.Stdout)
+	if !*jsonOutput {
+		fmt.Fprintln(os.Stderr, "Watching for events (Ctrl+C to stop)...")
+	}
+
+	return client.Subscribe(topics, func(event events.Event) error {
+		if *jsonOutput {
+			return encoder.Encode(event)
+		}
+		reporter.ShowEvent(event)
+		return nil
+	})
+}
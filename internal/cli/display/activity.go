@@ -2,6 +2,7 @@ package display
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -23,20 +24,80 @@ func (r *ActivityReporter) ShowSummary(logs []repository.ActivityLog) {
 		return
 	}
 
+	active := activeLogs(logs)
+	idle := logsInState(logs, repository.StateIdle)
+	suspended := logsInState(logs, repository.StateSuspended)
+	paused := logsInState(logs, repository.StatePaused)
+
 	fmt.Println("Activity Logs")
 	fmt.Println("=============")
-	fmt.Printf("Total records: %d (≈ %d minutes)\n\n", len(logs), len(logs)*5)
+	fmt.Printf("Total records: %d (≈ %.0f minutes)\n", len(active), totalMinutes(active))
+	if manual := manualLogs(active); len(manual) > 0 {
+		fmt.Printf("Manual:        %d (≈ %.0f minutes logged offline)\n", len(manual), totalMinutes(manual))
+	}
+	if len(idle) > 0 {
+		fmt.Printf("Idle records:  %d (≈ %.0f minutes away)\n", len(idle), totalMinutes(idle))
+	}
+	if len(suspended) > 0 {
+		fmt.Printf("Suspended:     %d times (≈ %.0f minutes asleep)\n", len(suspended), totalMinutes(suspended))
+	}
+	if len(paused) > 0 {
+		fmt.Printf("Paused:        %d records (≈ %.0f minutes not tracked)\n", len(paused), totalMinutes(paused))
+	}
+	fmt.Println()
 
-	r.showHourlyBreakdown(logs)
+	r.showHourlyBreakdown(active)
+}
+
+// totalMinutes sums the time represented by the records
+func totalMinutes(logs []repository.ActivityLog) float64 {
+	var minutes float64
+	for _, log := range logs {
+		minutes += log.Minutes()
+	}
+	return minutes
+}
+
+// logsInState returns the records in the given state
+func logsInState(logs []repository.ActivityLog, state string) []repository.ActivityLog {
+	var matched []repository.ActivityLog
+	for _, log := range logs {
+		if log.State == state {
+			matched = append(matched, log)
+		}
+	}
+	return matched
+}
+
+// manualLogs returns the records derived from manual entries
+func manualLogs(logs []repository.ActivityLog) []repository.ActivityLog {
+	var matched []repository.ActivityLog
+	for _, log := range logs {
+		if log.Source == repository.SourceManual {
+			matched = append(matched, log)
+		}
+	}
+	return matched
+}
+
+// activeLogs filters out records that don't count as active time
+func activeLogs(logs []repository.ActivityLog) []repository.ActivityLog {
+	active := make([]repository.ActivityLog, 0, len(logs))
+	for _, log := range logs {
+		if log.IsActive() {
+			active = append(active, log)
+		}
+	}
+	return active
 }
 
 // showHourlyBreakdown displays activity grouped by hour with visual bars
 func (r *ActivityReporter) showHourlyBreakdown(logs []repository.ActivityLog) {
 	// Group by hour for display
-	hourlyCount := make(map[string]int)
+	hourlyMinutes := make(map[string]float64)
 	for _, log := range logs {
 		hour := log.RecordedAt.Format("2006-01-02 15:00")
-		hourlyCount[hour]++
+		hourlyMinutes[hour] += log.Minutes()
 	}
 
 	// Find the time range to fill in missing hours
@@ -66,24 +127,28 @@ func (r *ActivityReporter) showHourlyBreakdown(logs []repository.ActivityLog) {
 	for h := startHour; !h.After(endHour); h = h.Add(time.Hour) {
 		hourStr := h.Format("2006-01-02 15:00")
 		hours = append(hours, hourStr)
-		// Initialize zero count for hours without activity
-		if _, exists := hourlyCount[hourStr]; !exists {
-			hourlyCount[hourStr] = 0
+		// Initialize zero minutes for hours without activity
+		if _, exists := hourlyMinutes[hourStr]; !exists {
+			hourlyMinutes[hourStr] = 0
 		}
 	}
 
 	fmt.Println("Activity by hour:")
 	for _, hour := range hours {
-		count := hourlyCount[hour]
-		bar := r.makeBar(count)
-		fmt.Printf("%s: %s (%d)\n", hour, bar, count*5)
+		minutes := hourlyMinutes[hour]
+		bar := r.makeBar(minutes)
+		fmt.Printf("%s: %s (%.0f)\n", hour, bar, minutes)
 	}
 }
 
-// makeBar creates a visual bar representation
-func (r *ActivityReporter) makeBar(count int) string {
-	if count == 0 {
+// makeBar creates a visual bar representation (one block per 5 minutes)
+func (r *ActivityReporter) makeBar(minutes float64) string {
+	blocks := int(math.Round(minutes / 5))
+	if blocks == 0 {
+		if minutes > 0 {
+			return "▏"
+		}
 		return "-"
 	}
-	return strings.Repeat("█", count)
+	return strings.Repeat("█", blocks)
 }
\ No newline at end of file
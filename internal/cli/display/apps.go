@@ -0,0 +1,90 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"shien/internal/service"
+)
+
+// AppsReporter handles the display of apps and their categorization
+type AppsReporter struct{}
+
+// NewAppsReporter creates a new apps reporter
+func NewAppsReporter() *AppsReporter {
+	return &AppsReporter{}
+}
+
+// ShowApps displays raw app names, their category and the rule that matched
+func (r *AppsReporter) ShowApps(report *service.AppsReport) {
+	fmt.Println("Apps")
+	fmt.Println("====")
+	fmt.Printf("Rules file: %s\n", report.RulesFile)
+	if report.RulesErr != "" {
+		fmt.Printf("⚠️  Rules file not applied: %s\n", report.RulesErr)
+	}
+	fmt.Println()
+
+	if len(report.Apps) == 0 {
+		fmt.Println("No apps recorded for the specified period")
+		return
+	}
+
+	fmt.Printf("%-28s %-20s %-30s %8s\n", "Raw name", "Category", "Rule", "Time")
+	stale := 0
+	for _, app := range report.Apps {
+		rule := "(no rule)"
+		if app.Rule != nil {
+			rule = app.Source + ": " + app.Rule.String()
+		}
+
+		category := app.CurrentCategory
+		if app.StoredCategory != app.CurrentCategory {
+			// Recorded before the rules changed
+			category += "*"
+			stale++
+		}
+
+		fmt.Printf("%-28s %-20s %-30s %8s\n", app.RawAppName, category, rule, formatMinutes(app.Minutes))
+	}
+
+	if stale > 0 {
+		fmt.Println()
+		fmt.Printf("* Recorded under a different category than the current rules give (%d apps)\n", stale)
+	}
+}
+
+// ShowRecategorize displays the outcome of re-applying the rules
+func (r *AppsReporter) ShowRecategorize(result *service.RecategorizeResult) {
+	title := "Recategorize"
+	if result.DryRun {
+		title += " (dry run)"
+	}
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("=", len(title)))
+	fmt.Printf("Range: %s - %s\n", result.From.Format("2006-01-02 15:04"), result.To.Format("2006-01-02 15:04"))
+	fmt.Println()
+
+	if len(result.Changes) == 0 {
+		fmt.Println("All records already match the current rules")
+	} else {
+		fmt.Printf("%-28s %-20s    %-20s %6s\n", "Raw name", "From", "To", "Rows")
+		for _, change := range result.Changes {
+			fmt.Printf("%-28s %-20s -> %-20s %6d\n", change.RawAppName, change.From, change.To, change.Rows)
+		}
+		fmt.Println()
+
+		if result.DryRun {
+			fmt.Printf("%d rows would change. Run again without -dry-run to apply.\n", result.RowsChanged)
+		} else {
+			fmt.Printf("%d rows changed\n", result.RowsChanged)
+			if result.SessionsRebuilt {
+				fmt.Println("Sessions in the range were rebuilt")
+			}
+		}
+	}
+
+	if result.RowsSkipped > 0 {
+		fmt.Printf("%d older rows have no raw app name and were left unchanged\n", result.RowsSkipped)
+	}
+}
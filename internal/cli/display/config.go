@@ -0,0 +1,108 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"shien/internal/config"
+	"shien/internal/service"
+)
+
+// ConfigReporter handles the display of configuration settings
+type ConfigReporter struct{}
+
+// NewConfigReporter creates a new config reporter
+func NewConfigReporter() *ConfigReporter {
+	return &ConfigReporter{}
+}
+
+// ShowDescription displays every setting with its value, source and constraints
+func (r *ConfigReporter) ShowDescription(description *service.ConfigDescription) {
+	fmt.Println("Configuration")
+	fmt.Println("=============")
+	fmt.Printf("File: %s\n", description.File)
+	if description.LoadError != "" {
+		fmt.Printf("⚠️  File not applied: %s\n", description.LoadError)
+	}
+
+	showWarnings(description.Warnings)
+
+	for _, setting := range description.Settings {
+		fmt.Println()
+		fmt.Printf("%s = %s  (%s)\n", setting.Key, formatValue(setting.Value), formatSource(setting))
+		fmt.Printf("    %s\n", setting.Description)
+		fmt.Printf("    %s, default %s\n", describeType(setting.Option), formatValue(setting.Default))
+		fmt.Printf("    Override with %s or -%s\n", setting.EnvVar(), setting.Flag())
+	}
+}
+
+// ShowSettings displays the effective value of every setting and its source
+func (r *ConfigReporter) ShowSettings(description *service.ConfigDescription) {
+	fmt.Println("Current Configuration")
+	fmt.Println("====================")
+	fmt.Printf("File: %s\n", description.File)
+	if description.LoadError != "" {
+		fmt.Printf("⚠️  File not applied: %s\n", description.LoadError)
+	}
+	showWarnings(description.Warnings)
+	fmt.Println()
+
+	fmt.Printf("%-24s %-30s %s\n", "Setting", "Value", "Source")
+	for _, setting := range description.Settings {
+		fmt.Printf("%-24s %-30s %s\n", setting.Key, formatValue(setting.Value), formatSource(setting))
+	}
+}
+
+// showWarnings displays ignored environment and flag settings
+func showWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+}
+
+// formatSource names the layer a value comes from, with the variable or flag
+func formatSource(setting config.Setting) string {
+	switch setting.Source {
+	case config.SourceEnv:
+		return "env " + setting.EnvVar()
+	case config.SourceFlag:
+		return "flag -" + setting.Flag()
+	default:
+		return string(setting.Source)
+	}
+}
+
+// describeType summarizes the type and constraints of an option
+func describeType(option config.Option) string {
+	text := string(option.Type)
+	switch {
+	case len(option.Enum) > 0:
+		values, _ := json.Marshal(option.Enum)
+		text += " one of " + string(values)
+	case option.Min > 0 && option.Max > 0:
+		text += fmt.Sprintf(" from %s to %s", option.Min, option.Max)
+	case option.Min > 0:
+		text += fmt.Sprintf(" of at least %s", option.Min)
+	case option.Max > 0:
+		text += fmt.Sprintf(" up to %s", option.Max)
+	case option.MinInt != nil && option.MaxInt != nil:
+		text += fmt.Sprintf(" from %d to %d", *option.MinInt, *option.MaxInt)
+	case option.MinInt != nil:
+		text += fmt.Sprintf(" of at least %d", *option.MinInt)
+	case option.MaxInt != nil:
+		text += fmt.Sprintf(" up to %d", *option.MaxInt)
+	}
+	return text
+}
+
+// formatValue formats a setting value as compact JSON
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(data) > 30 {
+		return string(data[:27]) + "..."
+	}
+	return string(data)
+}
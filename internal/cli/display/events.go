@@ -0,0 +1,66 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"shien/internal/events"
+)
+
+// EventReporter handles the display of live daemon events
+type EventReporter struct{}
+
+// NewEventReporter creates a new event reporter
+func NewEventReporter() *EventReporter {
+	return &EventReporter{}
+}
+
+// ShowEvent displays a single event on one line
+func (r *EventReporter) ShowEvent(event events.Event) {
+	fmt.Printf("%s  %-30s %s\n", eventTime(event.Time), event.Topic, describeEvent(event))
+}
+
+// describeEvent summarizes the data of an event
+func describeEvent(event events.Event) string {
+	data, err := events.DecodeData(event)
+	if err != nil {
+		return err.Error()
+	}
+
+	switch e := data.(type) {
+	case events.ActivityRecorded:
+		text := fmt.Sprintf("%s %s at %s", e.State, formatSessionDuration(e.Duration()), e.At.Local().Format("15:04"))
+		if e.AppName != "" {
+			text = e.AppName + " (" + text + ")"
+		}
+		return text
+	case events.LevelUp:
+		return fmt.Sprintf("🎉 Level %d → %d (%d total exp)", e.PreviousLevel, e.Level, e.TotalExp)
+	case events.ModifierApplied:
+		text := fmt.Sprintf("%s %+d: %s", e.Attribute, e.Value, e.Reason)
+		if e.ExpiresAt != nil {
+			text += " (until " + e.ExpiresAt.Local().Format("15:04") + ")"
+		}
+		return text
+	case events.ConfigChanged:
+		return strings.Join(e.Changed, ", ")
+	case events.PauseChanged:
+		if !e.Paused {
+			return "Tracking resumed"
+		}
+		if e.Until != nil {
+			return "Tracking paused until " + e.Until.Local().Format("15:04")
+		}
+		return "Tracking paused until resumed"
+	default:
+		raw, _ := json.Marshal(data)
+		return string(raw)
+	}
+}
+
+// eventTime formats an event time for display
+func eventTime(t time.Time) string {
+	return t.Local().Format("15:04:05")
+}
@@ -0,0 +1,46 @@
+package display
+
+import (
+	"fmt"
+
+	"shien/internal/rpc/registry"
+)
+
+// MethodsReporter handles the display of the RPC methods of the daemon
+type MethodsReporter struct{}
+
+// NewMethodsReporter creates a new methods reporter
+func NewMethodsReporter() *MethodsReporter {
+	return &MethodsReporter{}
+}
+
+// ShowMethods displays every method with its description and params
+func (r *MethodsReporter) ShowMethods(methods []registry.Method) {
+	fmt.Println("RPC Methods")
+	fmt.Println("===========")
+	for _, method := range methods {
+		fmt.Println()
+		r.ShowMethod(method)
+	}
+}
+
+// ShowMethod displays a method with its params and result
+func (r *MethodsReporter) ShowMethod(method registry.Method) {
+	fmt.Printf("%s  %s\n", method.Name, method.Description)
+	for _, param := range method.Params {
+		required := ""
+		if param.Required {
+			required = ", required"
+		}
+		fmt.Printf("    %-12s %s%s", param.Name, param.Type, required)
+		if param.Description != "" {
+			fmt.Printf("  %s", param.Description)
+		}
+		fmt.Println()
+	}
+	scope := "admin"
+	if method.ReadOnly {
+		scope = "read"
+	}
+	fmt.Printf("    → %s  (%s scope)\n", method.Result, scope)
+}
@@ -0,0 +1,87 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/service"
+)
+
+// ProjectReporter handles the display of projects, rules and project reports
+type ProjectReporter struct{}
+
+// NewProjectReporter creates a new project reporter
+func NewProjectReporter() *ProjectReporter {
+	return &ProjectReporter{}
+}
+
+// ShowProjects displays the list of projects
+func (r *ProjectReporter) ShowProjects(projects []repository.Project) {
+	if len(projects) == 0 {
+		fmt.Println("No projects yet. Create one with: shien project create <name>")
+		return
+	}
+
+	fmt.Println("Projects")
+	fmt.Println("========")
+	for _, project := range projects {
+		fmt.Printf("  %-4d %-24s %s\n", project.ID, project.Name, project.Description)
+	}
+}
+
+// ShowRules displays the project assignment rules
+func (r *ProjectReporter) ShowRules(rules []repository.ProjectRule) {
+	if len(rules) == 0 {
+		fmt.Println("No project rules defined")
+		return
+	}
+
+	fmt.Println("Project Rules")
+	fmt.Println("=============")
+	fmt.Printf("  %-4s %-6s %-8s %-30s %s\n", "ID", "Kind", "Priority", "Pattern", "Project")
+	for _, rule := range rules {
+		fmt.Printf("  %-4d %-6s %-8d %-30s %s\n", rule.ID, rule.Kind, rule.Priority, rule.Pattern, rule.ProjectName)
+	}
+}
+
+// ShowReport displays the time attributed to each project and tag
+func (r *ProjectReporter) ShowReport(report *service.ProjectReport) {
+	fmt.Printf("Project Report (%s - %s)\n", report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04"))
+	fmt.Println("==============")
+
+	if len(report.Projects) == 0 {
+		fmt.Println("No activity found for the specified period")
+		return
+	}
+
+	for _, usage := range report.Projects {
+		name := usage.Name
+		if usage.ProjectID == nil {
+			name = "(unassigned)"
+		}
+		fmt.Printf("  %-24s %8s  %s\n", name, formatMinutes(usage.Minutes), durationBar(time.Duration(usage.Minutes)*time.Minute))
+	}
+
+	if len(report.Tags) > 0 {
+		fmt.Println()
+		fmt.Println("Tags")
+		fmt.Println("----")
+
+		tags := make([]string, 0, len(report.Tags))
+		for tag := range report.Tags {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+
+		for _, tag := range tags {
+			fmt.Printf("  %-24s %8s\n", tag, formatMinutes(report.Tags[tag]))
+		}
+	}
+}
+
+// formatMinutes formats minutes as hours and minutes
+func formatMinutes(minutes int) string {
+	return formatSessionDuration(time.Duration(minutes) * time.Minute)
+}
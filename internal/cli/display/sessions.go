@@ -0,0 +1,78 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"shien/internal/database/repository"
+)
+
+// SessionReporter handles the display of work sessions
+type SessionReporter struct{}
+
+// NewSessionReporter creates a new session reporter
+func NewSessionReporter() *SessionReporter {
+	return &SessionReporter{}
+}
+
+// ShowTimeline displays sessions grouped by day in chronological order
+func (r *SessionReporter) ShowTimeline(sessions []repository.Session) {
+	if len(sessions) == 0 {
+		fmt.Println("No sessions found for the specified period")
+		return
+	}
+
+	fmt.Println("Work Sessions")
+	fmt.Println("=============")
+
+	var total time.Duration
+	currentDay := ""
+	for _, session := range sessions {
+		start := session.StartedAt.Time
+		end := session.EndedAt.Time
+
+		day := start.Format("2006-01-02 (Mon)")
+		if day != currentDay {
+			if currentDay != "" {
+				fmt.Println()
+			}
+			fmt.Println(day)
+			currentDay = day
+		}
+
+		fmt.Printf("  %s - %s  %7s  %-20s %s\n",
+			start.Format("15:04"),
+			end.Format("15:04"),
+			formatSessionDuration(session.Duration()),
+			session.AppName,
+			durationBar(session.Duration()))
+		total += session.Duration()
+	}
+
+	fmt.Println()
+	fmt.Printf("Total: %d sessions, %s\n", len(sessions), formatSessionDuration(total))
+}
+
+// durationBar creates a visual bar representation (one block per 15 minutes)
+func durationBar(d time.Duration) string {
+	blocks := int(d / (15 * time.Minute))
+	if blocks == 0 && d > 0 {
+		return "▏"
+	}
+	return strings.Repeat("█", blocks)
+}
+
+// formatSessionDuration formats a duration as hours and minutes
+func formatSessionDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	var b strings.Builder
+	if hours > 0 {
+		fmt.Fprintf(&b, "%dh", hours)
+	}
+	fmt.Fprintf(&b, "%dm", minutes)
+	return b.String()
+}
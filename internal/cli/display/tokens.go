@@ -0,0 +1,30 @@
+package display
+
+import (
+	"fmt"
+
+	"shien/internal/auth"
+)
+
+// TokenReporter handles the display of client tokens
+type TokenReporter struct{}
+
+// NewTokenReporter creates a new token reporter
+func NewTokenReporter() *TokenReporter {
+	return &TokenReporter{}
+}
+
+// ShowTokens displays the client tokens
+func (r *TokenReporter) ShowTokens(tokens []auth.Token) {
+	if len(tokens) == 0 {
+		fmt.Println("No client tokens. Create one with: shien token create <name>")
+		return
+	}
+
+	fmt.Println("Client Tokens")
+	fmt.Println("=============")
+	fmt.Printf("  %-24s %-6s %s\n", "Name", "Scope", "Created")
+	for _, token := range tokens {
+		fmt.Printf("  %-24s %-6s %s\n", token.Name, token.Scope, token.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+}
@@ -22,6 +22,7 @@ func (r *WeeklyReporter) ShowDailySummary(logs []repository.ActivityLog) {
 	fmt.Println("Weekly Activity - Daily Summary")
 	fmt.Println("================================")
 	
+	logs = activeLogs(logs)
 	if len(logs) == 0 {
 		fmt.Println("No activity logs found for the last 7 days")
 		return
@@ -29,9 +30,11 @@ func (r *WeeklyReporter) ShowDailySummary(logs []repository.ActivityLog) {
 
 	// Group by day
 	dailyActivity := make(map[string]int)
+	dailyMinutes := make(map[string]float64)
 	for _, log := range logs {
 		day := log.RecordedAt.Format("2006-01-02")
 		dailyActivity[day]++
+		dailyMinutes[day] += log.Minutes()
 	}
 
 	// Get all 7 days including those with no activity
@@ -49,8 +52,7 @@ func (r *WeeklyReporter) ShowDailySummary(logs []repository.ActivityLog) {
 	fmt.Println("\nLast 7 days:")
 	for _, day := range days {
 		count := dailyActivity[day]
-		minutes := count * 5
-		hours := float64(minutes) / 60.0
+		hours := dailyMinutes[day] / 60.0
 		// Scale bar: 0-24 hours (1440 minutes) = 0-100% of bar width
 		bar := r.makeAbsoluteBar(hours, 24.0, 30)
 		
@@ -64,8 +66,7 @@ func (r *WeeklyReporter) ShowDailySummary(logs []repository.ActivityLog) {
 	
 	// Total summary
 	totalRecords := len(logs)
-	totalMinutes := totalRecords * 5
-	totalHours := float64(totalMinutes) / 60.0
+	totalHours := totalMinutes(logs) / 60.0
 	fmt.Printf("\nTotal: %.1f hours (%d records)\n", totalHours, totalRecords)
 }
 
@@ -74,6 +75,7 @@ func (r *WeeklyReporter) ShowHourlyAverage(logs []repository.ActivityLog) {
 	fmt.Println("Weekly Activity - Hourly Average")
 	fmt.Println("=================================")
 	
+	logs = activeLogs(logs)
 	if len(logs) == 0 {
 		fmt.Println("No activity logs found for the last 7 days")
 		return
@@ -81,11 +83,13 @@ func (r *WeeklyReporter) ShowHourlyAverage(logs []repository.ActivityLog) {
 
 	// Group by hour of day
 	hourlyActivity := make(map[int]int)
+	hourlyMinutes := make(map[int]float64)
 	daysWithActivity := make(map[string]bool)
 	
 	for _, log := range logs {
 		hour := log.RecordedAt.Time.Hour()
 		hourlyActivity[hour]++
+		hourlyMinutes[hour] += log.Minutes()
 		day := log.RecordedAt.Format("2006-01-02")
 		daysWithActivity[day] = true
 	}
@@ -98,7 +102,7 @@ func (r *WeeklyReporter) ShowHourlyAverage(logs []repository.ActivityLog) {
 	for hour := 0; hour < 24; hour++ {
 		count := hourlyActivity[hour]
 		avgRecords := float64(count) / float64(numDays)
-		avgMinutes := avgRecords * 5
+		avgMinutes := hourlyMinutes[hour] / float64(numDays)
 		// Scale bar: 0-60 minutes = 0-100% of bar width
 		bar := r.makeAbsoluteBar(avgMinutes, 60.0, 30)
 		
@@ -109,24 +113,25 @@ func (r *WeeklyReporter) ShowHourlyAverage(logs []repository.ActivityLog) {
 	// Peak hours
 	fmt.Println("\nPeak activity hours:")
 	type hourCount struct {
-		hour int
-		avg  float64
+		hour       int
+		avg        float64
+		avgMinutes float64
 	}
 	
 	var hours []hourCount
 	for h, c := range hourlyActivity {
-		hours = append(hours, hourCount{h, float64(c) / float64(numDays)})
+		hours = append(hours, hourCount{h, float64(c) / float64(numDays), hourlyMinutes[h] / float64(numDays)})
 	}
 	
 	sort.Slice(hours, func(i, j int) bool {
-		return hours[i].avg > hours[j].avg
+		return hours[i].avgMinutes > hours[j].avgMinutes
 	})
 	
 	// Show top 3 hours
 	for i := 0; i < 3 && i < len(hours); i++ {
-		if hours[i].avg > 0 {
+		if hours[i].avgMinutes > 0 {
 			fmt.Printf("  %02d:00 - %.1f records/day (%.1f minutes/day)\n", 
-				hours[i].hour, hours[i].avg, hours[i].avg*5)
+				hours[i].hour, hours[i].avg, hours[i].avgMinutes)
 		}
 	}
 }
@@ -1,11 +1,20 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"os"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 	
+	"shien/internal/filewatch"
 	"shien/internal/paths"
+	"shien/internal/privacy"
 )
 
 // Config holds application configuration
@@ -17,6 +26,25 @@ type Config struct {
 	// Application settings
 	StartOnLogin          bool   `json:"start_on_login"`
 	ShowInDock            bool   `json:"show_in_dock"`
+	
+	// Activity tracking settings
+	SampleInterval        Duration `json:"sample_interval"`     // How often the foreground app is sampled
+	ForegroundProvider    string   `json:"foreground_provider"` // "os" or "command"
+	ForegroundCommand     string   `json:"foreground_command"`  // Shell command printing the app name
+	IdleThreshold         Duration `json:"idle_threshold"`      // Idle time after which samples are idle (0 disables)
+	SessionGapTolerance   Duration `json:"session_gap_tolerance"` // Largest gap that still continues a session
+	
+	// Gamification settings
+	GamificationEnabled   bool   `json:"gamification_enabled"` // Whether activity earns experience and attributes
+	
+	// Privacy settings
+	PrivacyRules          []privacy.Rule `json:"privacy_rules"` // Windows that are dropped, recorded as private or redacted
+	
+	// HTTP gateway settings
+	HTTPPort              int    `json:"http_port"` // Localhost port of the HTTP gateway (0 disables it)
+	
+	// Access control settings
+	RPCDefaultScope       string `json:"rpc_default_scope"` // Scope of socket clients without a token
 }
 
 // DefaultConfig returns default configuration
@@ -26,28 +54,71 @@ func DefaultConfig() *Config {
 		NotificationSound:     "default",
 		StartOnLogin:          false,
 		ShowInDock:            false,
+		SampleInterval:        Duration(5 * time.Minute),
+		ForegroundProvider:    "os",
+		ForegroundCommand:     "",
+		IdleThreshold:         Duration(5 * time.Minute),
+		SessionGapTolerance:   Duration(2 * time.Minute),
+		GamificationEnabled:   true,
+		PrivacyRules:          privacy.DefaultRules(),
+		HTTPPort:              0,
+		RPCDefaultScope:       "admin",
 	}
 }
 
-// Manager handles configuration persistence
+// Subscriber is notified after the configuration changed
+type Subscriber func(old, new *Config)
+
+// Manager handles configuration persistence and resolves the effective
+// configuration from its layers: defaults < config.json < SHIEN_*
+// environment variables < daemon flags
 type Manager struct {
-	config     *Config
-	configPath string
-	mu         sync.RWMutex
+	config      *Config   // Effective configuration
+	file        *Config   // Defaults overlaid with config.json, as saved
+	env         Overrides // Settings from environment variables
+	flags       Overrides // Settings from daemon flags
+	warnings    []string  // Overrides that were ignored
+	configPath  string
+	subscribers map[int]Subscriber
+	nextID      int
+	loadErr     error // Why the file was last rejected, nil when in effect
+	mu          sync.RWMutex
 }
 
-// NewManager creates a new config manager
-func NewManager() (*Manager, error) {
+// NewManager creates a new config manager. flags holds the settings given
+// as daemon flags, nil when there are none.
+func NewManager(flags Overrides) (*Manager, error) {
 	configPath := paths.ConfigFile()
 	
 	m := &Manager{
 		configPath: configPath,
-		config:     DefaultConfig(),
+		file:       DefaultConfig(),
+	}
+	
+	// Invalid overrides are ignored one by one so the others still apply
+	var errs []error
+	m.env, errs = EnvOverrides().valid(SourceEnv)
+	var flagErrs []error
+	m.flags, flagErrs = flags.valid(SourceFlag)
+	for _, err := range append(errs, flagErrs...) {
+		log.Print(err)
+		m.warnings = append(m.warnings, err.Error())
+	}
+	
+	// Settings that are only invalid together fall back to the defaults
+	cfg, err := m.resolve(m.file)
+	if err != nil {
+		log.Printf("Ignoring environment and flag settings: %v", err)
+		m.warnings = append(m.warnings, fmt.Sprintf("ignoring environment and flag settings: %v", err))
+		m.env, m.flags = nil, nil
+		cfg = DefaultConfig()
 	}
+	m.config = cfg
 	
-	// Load existing config if available
+	// Load existing config if available. An invalid file leaves the
+	// defaults in effect until it is fixed (see Watch).
 	if err := m.Load(); err != nil && !os.IsNotExist(err) {
-		return nil, err
+		log.Printf("Invalid config %s: %v, using defaults", configPath, err)
 	}
 	
 	// Save default config if not exists
@@ -60,38 +131,292 @@ func NewManager() (*Manager, error) {
 	return m, nil
 }
 
+// resolve applies the environment and flag settings over the file layer
+// and validates the result
+func (m *Manager) resolve(file *Config) (*Config, error) {
+	cfg := *file
+	if err := m.env.apply(&cfg); err != nil {
+		return nil, err
+	}
+	if err := m.flags.apply(&cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
 // Get returns current configuration
 func (m *Manager) Get() *Config {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
 	// Return a copy to prevent external modifications
-	cfg := *m.config
+	cfg := m.current()
 	return &cfg
 }
 
-// Update updates configuration
-func (m *Manager) Update(fn func(*Config)) error {
+// current returns a copy of the effective configuration.
+// Must be called with the lock held.
+func (m *Manager) current() Config {
+	if m.config == nil {
+		return *DefaultConfig()
+	}
+	return *m.config
+}
+
+// Source returns which layer the effective value of a setting comes from.
+// Values in config.json equal to the default are reported as defaults.
+func (m *Manager) Source(key string) Source {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	if _, ok := m.flags[key]; ok {
+		return SourceFlag
+	}
+	if _, ok := m.env[key]; ok {
+		return SourceEnv
+	}
+	if m.file != nil {
+		value, _ := m.file.Value(key)
+		if !reflect.DeepEqual(value, Option{Key: key}.Default()) {
+			return SourceFile
+		}
+	}
+	return SourceDefault
+}
+
+// Warnings returns the environment and flag settings that were ignored
+func (m *Manager) Warnings() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.warnings...)
+}
+
+// InvalidUpdateError is returned by Update when a change is rejected because
+// of the new values rather than because saving them failed
+type InvalidUpdateError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *InvalidUpdateError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *InvalidUpdateError) Unwrap() error {
+	return e.Err
+}
+
+// Update changes the settings saved in config.json and notifies subscribers
+// of the change. fn modifies the file layer while the lock is held, so
+// concurrent updates never work from a stale copy; environment and flag
+// settings still take precedence. Nothing is saved if fn fails or the result
+// is invalid, which is reported as an *InvalidUpdateError. It returns the
+// effective configuration before and after the change.
+func (m *Manager) Update(fn func(*Config) error) (old, updated *Config, err error) {
+	m.mu.Lock()
+	
+	if m.file == nil {
+		m.file = DefaultConfig()
+	}
+	before := m.current()
+	
+	file := *m.file
+	if err := fn(&file); err != nil {
+		m.mu.Unlock()
+		return nil, nil, &InvalidUpdateError{Err: err}
+	}
+	cfg, err := m.resolve(&file)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, nil, &InvalidUpdateError{Err: err}
+	}
+	
+	previous := m.file
+	m.file = &file
+	if err := m.Save(); err != nil {
+		m.file = previous
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+	
+	m.config = cfg
+	after := *cfg
+	m.loadErr = nil
+	subscribers := m.subscriberList()
+	m.mu.Unlock()
+	
+	// Subscribers get their own copies
+	old, updated = &Config{}, &Config{}
+	*old, *updated = before, after
+	notify(subscribers, &before, &after)
+	return old, updated, nil
+}
+
+// notify calls subscribers with a change. They run without the lock so
+// they can read the config.
+func notify(subscribers []Subscriber, old, new *Config) {
+	for _, fn := range subscribers {
+		fn(old, new)
+	}
+}
+
+// Subscribe registers fn to be called after every change.
+// The returned function removes the subscription.
+func (m *Manager) Subscribe(fn Subscriber) (unsubscribe func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	fn(m.config)
-	return m.Save()
+	if m.subscribers == nil {
+		m.subscribers = make(map[int]Subscriber)
+	}
+	id := m.nextID
+	m.nextID++
+	m.subscribers[id] = fn
+	
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subscribers, id)
+	}
+}
+
+// subscriberList returns the subscribers in registration order.
+// Must be called with the lock held.
+func (m *Manager) subscriberList() []Subscriber {
+	ids := make([]int, 0, len(m.subscribers))
+	for id := range m.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	
+	list := make([]Subscriber, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, m.subscribers[id])
+	}
+	return list
 }
 
-// Load loads configuration from file
+// Load loads configuration from file. Settings missing from the file take
+// their default value. If the file can't be read, parsed or validated, the
+// current configuration stays in effect and the error is kept for LoadError.
+// Subscribers are notified when the loaded settings differ.
 func (m *Manager) Load() error {
+	_, err := m.load()
+	return err
+}
+
+// load loads the config file and reports whether the settings changed
+func (m *Manager) load() (bool, error) {
+	file, err := m.read()
+	
+	m.mu.Lock()
+	var cfg *Config
+	if err == nil {
+		cfg, err = m.resolve(file)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", m.configPath, err)
+		}
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			m.loadErr = err
+		}
+		m.mu.Unlock()
+		return false, err
+	}
+	
+	m.loadErr = nil
+	m.file = file
+	if m.config != nil && reflect.DeepEqual(m.config, cfg) {
+		m.mu.Unlock()
+		return false, nil
+	}
+	
+	old := m.current()
+	m.config = cfg
+	updated := *cfg
+	subscribers := m.subscriberList()
+	m.mu.Unlock()
+	
+	notify(subscribers, &old, &updated)
+	return true, nil
+}
+
+// read parses the config file over the defaults
+func (m *Manager) read() (*Config, error) {
 	data, err := os.ReadFile(m.configPath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	
+	// Older files are upgraded before they are parsed
+	data, err = migrateFile(m.configPath, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.configPath, err)
+	}
+	
+	cfg, warnings, err := ParseFile(data)
+	for _, warning := range warnings {
+		log.Printf("%s: %s", m.configPath, warning)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.configPath, err)
+	}
+	return cfg, nil
+}
+
+// LoadError returns why the config file was last rejected, or nil if the
+// file's settings are in effect
+func (m *Manager) LoadError() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.loadErr
+}
+
+// Watch reloads the config file whenever it changes until ctx is canceled.
+// Invalid edits are logged and the last good configuration is kept.
+func (m *Manager) Watch(ctx context.Context) {
+	if m.configPath == "" {
+		return
 	}
 	
-	return json.Unmarshal(data, m.config)
+	reload := func() {
+		changed, err := m.load()
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Printf("Config file %s was removed, keeping current settings", m.configPath)
+			} else {
+				log.Printf("Ignoring config change: %v", err)
+			}
+			return
+		}
+		if changed {
+			log.Printf("Reloaded config from %s", m.configPath)
+		}
+	}
+	
+	// Pick up edits made since the config was first loaded
+	reload()
+	filewatch.Watch(ctx, m.configPath, filewatch.DefaultInterval, reload)
 }
 
-// Save saves configuration to file
+// Save saves the file layer of the configuration to config.json.
+// Environment and flag settings are never saved.
 func (m *Manager) Save() error {
-	data, err := json.MarshalIndent(m.config, "", "  ")
+	if m.configPath == "" {
+		return errors.New("no config file")
+	}
+	
+	file := m.file
+	if file == nil {
+		file = DefaultConfig()
+	}
+	
+	data, err := json.MarshalIndent(fileContents{Version: CurrentVersion(), Config: file}, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newTestManager returns a manager saving to a temporary config.json
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	return &Manager{configPath: filepath.Join(t.TempDir(), "config.json")}
+}
+
+func TestManagerUpdateIsAtomic(t *testing.T) {
+	m := newTestManager(t)
+
+	// Each update reads the port and writes it back incremented; a lost
+	// update would leave the port short
+	const updates = 20
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := m.Update(func(cfg *Config) error {
+				return cfg.Set("http_port", cfg.HTTPPort+1)
+			})
+			if err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := m.Get().HTTPPort; got != updates {
+		t.Errorf("http_port = %d, want %d", got, updates)
+	}
+}
+
+func TestManagerUpdateReturnsChange(t *testing.T) {
+	m := newTestManager(t)
+
+	old, updated, err := m.Update(func(cfg *Config) error {
+		return cfg.Set("http_port", 8787)
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if old.HTTPPort != 0 || updated.HTTPPort != 8787 {
+		t.Errorf("Update() = %d -> %d, want 0 -> 8787", old.HTTPPort, updated.HTTPPort)
+	}
+}
+
+func TestManagerUpdateRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(*Config) error
+	}{
+		{"wrong type", func(cfg *Config) error { return cfg.Set("http_port", "eighty") }},
+		{"invalid value", func(cfg *Config) error { return cfg.Set("rpc_default_scope", "root") }},
+		{"invalid combination", func(cfg *Config) error { return cfg.Set("foreground_provider", "command") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t)
+			if _, _, err := m.Update(func(cfg *Config) error { return cfg.Set("http_port", 8787) }); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			saved, err := os.ReadFile(m.ConfigPath())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = m.Update(tt.fn)
+			var invalid *InvalidUpdateError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Update() error = %v, want an *InvalidUpdateError", err)
+			}
+
+			// Neither the file nor the effective configuration changed
+			if data, _ := os.ReadFile(m.ConfigPath()); !bytes.Equal(data, saved) {
+				t.Errorf("config.json changed to %s", data)
+			}
+			if got := m.Get().HTTPPort; got != 8787 {
+				t.Errorf("http_port = %d, want 8787", got)
+			}
+		})
+	}
+}
+
+func TestValidateIntRange(t *testing.T) {
+	tests := []struct {
+		port    int
+		wantErr bool
+	}{
+		{-1, true},
+		{0, false},
+		{8787, false},
+		{65535, false},
+		{65536, true},
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.HTTPPort = tt.port
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with http_port %d error = %v, want error %v", tt.port, err, tt.wantErr)
+		}
+	}
+
+	// The range comes from the schema, where config describe shows it
+	option, _ := LookupOption("http_port")
+	if option.MinInt == nil || *option.MinInt != 0 || option.MaxInt == nil || *option.MaxInt != 65535 {
+		t.Errorf("http_port schema range = %v to %v, want 0 to 65535", option.MinInt, option.MaxInt)
+	}
+}
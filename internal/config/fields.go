@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Keys returns the JSON keys of all settings in alphabetical order
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := jsonKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set sets the setting with the given JSON key. The value must have the
+// JSON type of the setting (e.g. a bool, or a string for durations).
+func (c *Config) Set(key string, value interface{}) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q (known settings: %s)", key, strings.Join(Keys(), ", "))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	// Decode into a fresh value so a failed update leaves the field untouched
+	parsed := reflect.New(field.Type())
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(parsed.Interface()); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field == "" {
+			return fmt.Errorf("%s: expected %s, got %s", key, typeName(field.Type()), data)
+		}
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	field.Set(parsed.Elem())
+	return nil
+}
+
+// Value returns the setting with the given JSON key
+func (c *Config) Value(key string) (interface{}, bool) {
+	field, ok := c.field(key)
+	if !ok {
+		return nil, false
+	}
+	return field.Interface(), true
+}
+
+// field returns the struct field with the given JSON key
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonKey(t.Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonKey returns the JSON key of a struct field, or "" if it isn't encoded
+func jsonKey(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+	if name == "-" || name == "" {
+		return ""
+	}
+	return name
+}
+
+// typeName describes the JSON type expected for a Go type
+func typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(Duration(0)) {
+		return "a duration string like \"5m\""
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a JSON array"
+	default:
+		return "a JSON " + t.Kind().String()
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvVar returns the environment variable overriding the setting
+func (o Option) EnvVar() string {
+	return "SHIEN_" + strings.ToUpper(o.Key)
+}
+
+// Flag returns the daemon flag overriding the setting
+func (o Option) Flag() string {
+	return strings.ReplaceAll(o.Key, "_", "-")
+}
+
+// Overrides maps setting keys to values given as text, as in environment
+// variables and command-line flags
+type Overrides map[string]string
+
+// EnvOverrides returns the settings given as SHIEN_* environment variables
+func EnvOverrides() Overrides {
+	overrides := Overrides{}
+	for _, option := range Schema {
+		if value, ok := os.LookupEnv(option.EnvVar()); ok {
+			overrides[option.Key] = value
+		}
+	}
+	return overrides
+}
+
+// RegisterFlags defines a flag for every setting on fs (e.g. -idle-threshold)
+// that stores the given value in o
+func (o Overrides) RegisterFlags(fs *flag.FlagSet) {
+	for _, option := range Schema {
+		usage := option.Description
+		if option.Type == TypeRules {
+			usage += " (JSON array)"
+		}
+		fs.Var(&overrideFlag{overrides: o, option: option}, option.Flag(), usage)
+	}
+}
+
+// Keys returns the overridden keys in alphabetical order
+func (o Overrides) Keys() []string {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// apply sets the overridden settings on cfg
+func (o Overrides) apply(cfg *Config) error {
+	for _, key := range o.Keys() {
+		value, err := ParseText(key, o[key])
+		if err != nil {
+			return err
+		}
+		if err := cfg.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valid returns the overrides that can be applied on their own, and an
+// error for each of the others
+func (o Overrides) valid(source Source) (Overrides, []error) {
+	valid := Overrides{}
+	var errs []error
+	for _, key := range o.Keys() {
+		if err := (Overrides{key: o[key]}).apply(DefaultConfig()); err != nil {
+			errs = append(errs, fmt.Errorf("ignoring %s override: %w", source, err))
+			continue
+		}
+		valid[key] = o[key]
+	}
+	return valid, errs
+}
+
+// ParseText converts the text form of a setting, as given in environment
+// variables, flags and on the command line, into its JSON value
+func ParseText(key, text string) (interface{}, error) {
+	option, ok := LookupOption(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %q", key)
+	}
+
+	switch option.Type {
+	case TypeBool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected true or false, got %q", key, text)
+		}
+		return value, nil
+	case TypeInt:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected a whole number, got %q", key, text)
+		}
+		return value, nil
+	case TypeRules:
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("%s: expected a JSON array: %w", key, err)
+		}
+		return value, nil
+	default:
+		return text, nil
+	}
+}
+
+// overrideFlag is a flag.Value storing a setting override
+type overrideFlag struct {
+	overrides Overrides
+	option    Option
+}
+
+// String returns the value given on the command line
+func (f *overrideFlag) String() string {
+	if f.overrides == nil {
+		return ""
+	}
+	return f.overrides[f.option.Key]
+}
+
+// Set checks and stores the value
+func (f *overrideFlag) Set(text string) error {
+	if _, err := ParseText(f.option.Key, text); err != nil {
+		return err
+	}
+	f.overrides[f.option.Key] = text
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given without a value (-show-in-dock)
+func (f *overrideFlag) IsBoolFlag() bool {
+	return f.option.Type == TypeBool
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"shien/internal/config/migrations"
+)
+
+// versionKey is the config file key holding the file's version
+const versionKey = "version"
+
+// CurrentVersion returns the config file version written by this build
+func CurrentVersion() int {
+	all := migrations.All()
+	return all[len(all)-1].Version
+}
+
+// fileContents is the layout of config.json
+type fileContents struct {
+	Version int `json:"version"`
+	*Config
+}
+
+// Migrate upgrades the contents of a config file to the current version.
+// Files without a version are version 0. It returns the version the file
+// had and the upgraded contents, which are data itself if nothing changed.
+func Migrate(data []byte) (int, []byte, error) {
+	var settings migrations.Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		// Reported with its position when the file is parsed
+		return 0, data, nil
+	}
+
+	version := 0
+	if raw, ok := settings[versionKey]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return 0, nil, fmt.Errorf("version must be a number, got %s", raw)
+		}
+	}
+	if version >= CurrentVersion() {
+		return version, data, nil
+	}
+
+	for _, migration := range migrations.All() {
+		if migration.Version <= version {
+			continue
+		}
+
+		if err := migration.Up(settings); err != nil {
+			return version, nil, fmt.Errorf("config migration %d failed: %w", migration.Version, err)
+		}
+		settings[versionKey] = json.RawMessage(fmt.Sprint(migration.Version))
+	}
+
+	upgraded, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return version, nil, err
+	}
+	return version, upgraded, nil
+}
+
+// migrateFile upgrades the config file at path if it has an older version,
+// keeping the previous file as <path>.v<version>.bak. It returns the
+// current contents of the file.
+func migrateFile(path string, data []byte) ([]byte, error) {
+	version, upgraded, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentVersion() {
+		log.Printf("%s has version %d, newer than this build supports (%d)", path, version, CurrentVersion())
+	}
+	if string(upgraded) == string(data) {
+		return data, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to back up config before migrating: %w", err)
+	}
+	if err := os.WriteFile(path, upgraded, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write migrated config: %w", err)
+	}
+
+	log.Printf("Migrated %s from version %d to %d (previous file kept as %s)", path, version, CurrentVersion(), backup)
+	return upgraded, nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// readTestdata returns the contents of a file in testdata
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMigrateUnversioned(t *testing.T) {
+	version, upgraded, err := Migrate(readTestdata(t, "v0.json"))
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if version != 0 {
+		t.Errorf("version = %d, want 0", version)
+	}
+
+	golden := filepath.Join("testdata", "v0.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, upgraded, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := readTestdata(t, "v0.golden.json"); !bytes.Equal(upgraded, want) {
+		t.Errorf("Migrate() =\n%s\nwant\n%s", upgraded, want)
+	}
+
+	// The upgraded file is current
+	if got, _, err := Migrate(upgraded); err != nil || got != CurrentVersion() {
+		t.Errorf("Migrate(upgraded) version = %d, %v, want %d", got, err, CurrentVersion())
+	}
+}
+
+func TestMigrateCurrent(t *testing.T) {
+	data := readTestdata(t, "current.json")
+
+	version, upgraded, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if version != CurrentVersion() {
+		t.Errorf("version = %d, want %d", version, CurrentVersion())
+	}
+	if !bytes.Equal(upgraded, data) {
+		t.Errorf("Migrate() rewrote a current file:\n%s", upgraded)
+	}
+}
+
+func TestMigrateVersionNotANumber(t *testing.T) {
+	if _, _, err := Migrate(readTestdata(t, "version_string.json")); err == nil {
+		t.Error("Migrate() accepted a version that is not a number")
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	data := readTestdata(t, "version_newer.json")
+
+	version, upgraded, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if version != 99 {
+		t.Errorf("version = %d, want 99", version)
+	}
+	if !bytes.Equal(upgraded, data) {
+		t.Errorf("Migrate() rewrote a newer file:\n%s", upgraded)
+	}
+}
+
+func TestMigrateFileKeepsBackup(t *testing.T) {
+	data := readTestdata(t, "v0.json")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	upgraded, err := migrateFile(path, data)
+	if err != nil {
+		t.Fatalf("migrateFile() error = %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("no backup: %v", err)
+	}
+	if !bytes.Equal(backup, data) {
+		t.Errorf("backup =\n%s\nwant the original\n%s", backup, data)
+	}
+	if saved, _ := os.ReadFile(path); !bytes.Equal(saved, upgraded) {
+		t.Errorf("config.json =\n%s\nwant\n%s", saved, upgraded)
+	}
+
+	// Migrating again changes nothing and leaves the backup alone
+	if _, err := migrateFile(path, upgraded); err != nil {
+		t.Fatalf("migrateFile() again error = %v", err)
+	}
+	if again, _ := os.ReadFile(path + ".v0.bak"); !bytes.Equal(again, data) {
+		t.Error("backup was overwritten")
+	}
+}
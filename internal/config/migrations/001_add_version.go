@@ -0,0 +1,11 @@
+package migrations
+
+// Migration001_AddVersion marks files written before config files were
+// versioned. Their settings are unchanged; missing ones take their defaults.
+var Migration001_AddVersion = Migration{
+	Version:     1,
+	Description: "Add version field",
+	Up: func(settings Settings) error {
+		return nil
+	},
+}
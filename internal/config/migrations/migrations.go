@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"encoding/json"
+)
+
+// Settings holds the top-level keys of a config file with their raw values
+type Settings map[string]json.RawMessage
+
+// Migration upgrades a config file by one version
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(Settings) error
+}
+
+// All returns all migrations in order
+func All() []Migration {
+	return []Migration{
+		Migration001_AddVersion,
+		// Future migrations will be added here:
+		// Migration002_RenameSetting,
+	}
+}
+
+// Rename moves a setting to a new key, unless the new key is already set
+func (s Settings) Rename(from, to string) {
+	value, ok := s[from]
+	if !ok {
+		return
+	}
+	delete(s, from)
+	if _, exists := s[to]; !exists {
+		s[to] = value
+	}
+}
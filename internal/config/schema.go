@@ -0,0 +1,327 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Type is the JSON type of a setting
+type Type string
+
+// Setting types
+const (
+	TypeBool     Type = "bool"
+	TypeString   Type = "string"
+	TypeInt      Type = "int"
+	TypeDuration Type = "duration" // A string like "5m" or "1h30m"
+	TypeRules    Type = "rules"    // An array of objects
+)
+
+// Option describes a setting in config.json
+type Option struct {
+	Key         string   `json:"key"`
+	Type        Type     `json:"type"`
+	Description string   `json:"description"`
+	Enum        []string `json:"enum,omitempty"`    // Allowed values, empty for any
+	Min         Duration `json:"min,omitempty"`     // Smallest duration allowed
+	Max         Duration `json:"max,omitempty"`     // Largest duration allowed, 0 for no limit
+	MinInt      *int     `json:"min_int,omitempty"` // Smallest integer allowed, nil for no limit
+	MaxInt      *int     `json:"max_int,omitempty"` // Largest integer allowed, nil for no limit
+}
+
+// intLimit returns a pointer to an integer bound of an option
+func intLimit(n int) *int {
+	return &n
+}
+
+// Default returns the default value of the setting
+func (o Option) Default() interface{} {
+	value, _ := DefaultConfig().Value(o.Key)
+	return value
+}
+
+// Schema lists every setting in config.json
+var Schema = []Option{
+	{
+		Key:         "notification_enabled",
+		Type:        TypeBool,
+		Description: "Show notifications from the daemon",
+	},
+	{
+		Key:         "notification_sound",
+		Type:        TypeString,
+		Description: "Sound played with notifications, empty for none",
+	},
+	{
+		Key:         "start_on_login",
+		Type:        TypeBool,
+		Description: "Start the daemon when logging in",
+	},
+	{
+		Key:         "show_in_dock",
+		Type:        TypeBool,
+		Description: "Show the daemon in the macOS Dock",
+	},
+	{
+		Key:         "sample_interval",
+		Type:        TypeDuration,
+		Description: "How often the foreground app is sampled",
+		Min:         Duration(time.Minute),
+		Max:         Duration(time.Hour),
+	},
+	{
+		Key:         "foreground_provider",
+		Type:        TypeString,
+		Description: "How the foreground app is detected",
+		Enum:        []string{"os", "command"},
+	},
+	{
+		Key:         "foreground_command",
+		Type:        TypeString,
+		Description: "Shell command printing the foreground app name (foreground_provider \"command\")",
+	},
+	{
+		Key:         "idle_threshold",
+		Type:        TypeDuration,
+		Description: "Time without input after which samples are recorded as idle, 0 to disable",
+		Max:         Duration(24 * time.Hour),
+	},
+	{
+		Key:         "session_gap_tolerance",
+		Type:        TypeDuration,
+		Description: "Largest gap between samples that still continues a session",
+		Max:         Duration(time.Hour),
+	},
+	{
+		Key:         "gamification_enabled",
+		Type:        TypeBool,
+		Description: "Whether activity earns experience and attributes",
+	},
+	{
+		Key:         "privacy_rules",
+		Type:        TypeRules,
+		Description: "Windows that are dropped, recorded as private or have their details redacted",
+	},
+	{
+		Key:         "http_port",
+		Type:        TypeInt,
+		Description: "Port of the HTTP gateway on localhost, 0 to disable it",
+		MinInt:      intLimit(0),
+		MaxInt:      intLimit(65535),
+	},
+	{
+		Key:         "rpc_default_scope",
+		Type:        TypeString,
+		Description: "What socket clients without a token may call: everything, read-only methods or nothing. Client tokens only restrict access once this is read or none",
+		Enum:        []string{"admin", "read", "none"},
+	},
+}
+
+// LookupOption returns the schema entry for a key
+func LookupOption(key string) (Option, bool) {
+	for _, option := range Schema {
+		if option.Key == key {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// check validates a value against the option's type, range and enum
+func (o Option) check(value interface{}) error {
+	switch v := value.(type) {
+	case Duration:
+		if v < o.Min {
+			return fmt.Errorf("%s must be at least %s, got %s", o.Key, o.Min, v)
+		}
+		if o.Max > 0 && v > o.Max {
+			return fmt.Errorf("%s must be at most %s, got %s", o.Key, o.Max, v)
+		}
+	case int:
+		if o.MinInt != nil && v < *o.MinInt {
+			return fmt.Errorf("%s must be at least %d, got %d", o.Key, *o.MinInt, v)
+		}
+		if o.MaxInt != nil && v > *o.MaxInt {
+			return fmt.Errorf("%s must be at most %d, got %d", o.Key, *o.MaxInt, v)
+		}
+	case string:
+		if len(o.Enum) > 0 && v != "" && !contains(o.Enum, v) {
+			return fmt.Errorf("%s must be one of %s, got %q", o.Key, quoteAll(o.Enum), v)
+		}
+	}
+	return nil
+}
+
+// ParseFile parses the contents of a config file over the defaults.
+// Keys not in the schema are returned as warnings rather than errors so
+// that files written by newer versions still load. The settings are not
+// validated. If some settings have the wrong type, the error lists them and
+// the returned config holds the others (nil if the file isn't JSON).
+func ParseFile(data []byte) (*Config, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(data, syntaxErr.Offset)
+			return nil, nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+		return nil, nil, err
+	}
+
+	var warnings []string
+	for key := range raw {
+		if _, ok := LookupOption(key); ok || key == versionKey {
+			continue
+		}
+		if suggestion := closestKey(key); suggestion != "" {
+			warnings = append(warnings, fmt.Sprintf("unknown setting %q (did you mean %q?)", key, suggestion))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("unknown setting %q", key))
+		}
+	}
+	sort.Strings(warnings)
+
+	// Decode each key separately so errors name the setting
+	cfg := DefaultConfig()
+	var errs []error
+	for _, option := range Schema {
+		value, ok := raw[option.Key]
+		if !ok {
+			continue
+		}
+		if err := cfg.Set(option.Key, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return cfg, warnings, errors.Join(errs...)
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (int, int) {
+	line, column := 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// closestKey returns the known key nearest to a misspelled one, if any is close
+func closestKey(key string) string {
+	best, bestDistance := "", 4
+	for _, option := range Schema {
+		if d := editDistance(strings.ToLower(key), option.Key); d < bestDistance {
+			best, bestDistance = option.Key, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(b)]
+}
+
+// contains reports whether values contains s
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteAll formats values as a quoted, comma separated list
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Source tells where the effective value of a setting comes from
+type Source string
+
+// Setting sources, in increasing precedence
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file" // config.json
+	SourceEnv     Source = "env"  // SHIEN_* environment variables
+	SourceFlag    Source = "flag" // Daemon flags
+)
+
+// Setting is a schema option with its default and effective value
+type Setting struct {
+	Option
+	Default interface{} `json:"default"`
+	Value   interface{} `json:"value"`
+	Source  Source      `json:"source"`
+}
+
+// Describe returns every setting with its effective value and its source
+func (m *Manager) Describe() []Setting {
+	cfg := m.Get()
+	settings := make([]Setting, 0, len(Schema))
+	for _, option := range Schema {
+		value, _ := cfg.Value(option.Key)
+		setting := Setting{
+			Option:  option,
+			Default: option.Default(),
+			Value:   value,
+			Source:  m.Source(option.Key),
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// ValidateFile checks a config file without loading it. It returns
+// warnings for unknown keys and an error listing every invalid setting.
+func ValidateFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older files are checked as they will be after migrating
+	_, data, err = Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report invalid values of the settings that parsed as well
+	cfg, warnings, err := ParseFile(data)
+	if cfg == nil {
+		return warnings, err
+	}
+	return warnings, errors.Join(err, cfg.Validate())
+}
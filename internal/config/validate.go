@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"shien/internal/privacy"
+)
+
+// Validate checks the settings against the schema and each other.
+// All problems are reported.
+func (c *Config) Validate() error {
+	var errs []error
+
+	for _, option := range Schema {
+		value, _ := c.Value(option.Key)
+		if err := option.check(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.ForegroundProvider == "command" && c.ForegroundCommand == "" {
+		errs = append(errs, errors.New("foreground_command must be set when foreground_provider is \"command\""))
+	}
+
+	if _, err := privacy.NewPolicy(c.PrivacyRules); err != nil {
+		errs = append(errs, fmt.Errorf("privacy_rules: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,12 +2,17 @@ package daemon
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"shien/internal/config"
 	"shien/internal/database"
+	"shien/internal/foreground"
+	"shien/internal/idle"
 	"shien/internal/rpc"
+	"shien/internal/scheduler"
 	"shien/internal/service"
 	"shien/internal/tray"
 	"shien/internal/ui"
@@ -23,11 +28,16 @@ type Daemon struct {
 	repo      *database.Repository
 	services  *service.Services
 	rpcServer *rpc.Server
+	gateway   *rpc.Gateway // HTTP gateway, nil without an RPC server
+	stopOnce  sync.Once
+	resched   chan struct{} // Signals that the sample interval changed
 }
 
-func New() *Daemon {
+// New creates the daemon. configFlags holds the settings given as daemon
+// flags, which take precedence over the environment and config.json.
+func New(configFlags config.Overrides) *Daemon {
 	// Initialize config manager
-	configMgr, err := config.NewManager()
+	configMgr, err := config.NewManager(configFlags)
 	if err != nil {
 		log.Printf("Failed to initialize config: %v, using defaults", err)
 		configMgr = &config.Manager{}
@@ -42,8 +52,15 @@ func New() *Daemon {
 	// Create repository
 	repo := database.NewRepository(db)
 	
+	// Select the foreground app provider
+	provider, err := foreground.New(configMgr.Get())
+	if err != nil {
+		log.Printf("Failed to create foreground provider: %v, using OS detection", err)
+		provider = foreground.NewOSProvider()
+	}
+	
 	// Create service layer
-	services := service.NewServices(repo, configMgr)
+	services := service.NewServices(repo, configMgr, provider, idle.NewDefault())
 	
 	// Create RPC server
 	rpcServer, err := rpc.NewServer(services)
@@ -51,15 +68,41 @@ func New() *Daemon {
 		log.Printf("Failed to create RPC server: %v", err)
 	}
 
-	return &Daemon{
+	// Let the tray menu pause and resume tracking, and notify about events
+	t := tray.New()
+	cfg := configMgr.Get()
+	t.SetNotificationSettings(cfg.NotificationEnabled, cfg.NotificationSound)
+	if services != nil {
+		t.SetPauseController(&pauseController{pause: services.Pause})
+		t.Listen(services.Events)
+	}
+
+	d := &Daemon{
 		display:   ui.NewDisplay(),
-		tray:      tray.New(),
+		tray:      t,
 		config:    configMgr,
 		db:        db,
 		repo:      repo,
 		services:  services,
 		rpcServer: rpcServer,
+		resched:   make(chan struct{}, 1),
+	}
+	if rpcServer != nil {
+		d.gateway = rpc.NewGateway(rpcServer)
 	}
+	
+	// React to settings changed through the CLI or by editing the file
+	configMgr.Subscribe(d.configChanged)
+	
+	// Let clients stop the daemon with `shien stop`
+	if rpcServer != nil {
+		rpcServer.SetShutdownHandler(func() {
+			d.display.ShowInfo("Shutdown requested via RPC")
+			d.Stop()
+		})
+	}
+	
+	return d
 }
 
 func (d *Daemon) Start() error {
@@ -88,32 +131,53 @@ func (d *Daemon) Start() error {
 		}
 	}
 
+	// Start the HTTP gateway if a port is configured
+	if d.config != nil {
+		d.startGateway(d.config.Get().HTTPPort)
+	}
+
 	// Send notification via system tray
 	d.tray.SendNotification("Shien", "Support daemon started")
 
+	// Reload app name rules when the rules file is edited
+	if d.services != nil {
+		go d.services.Apps.Watch(d.ctx)
+	}
+
+	// Reload settings when the config file is edited
+	if d.config != nil {
+		go d.config.Watch(d.ctx)
+	}
+
 	// Start the daemon worker
 	go d.run()
 
 	return nil
 }
 
+// Stop stops the daemon. It is safe to call more than once.
 func (d *Daemon) Stop() error {
-	if d.cancel != nil {
-		d.cancel()
-	}
+	d.stopOnce.Do(func() {
+		if d.cancel != nil {
+			d.cancel()
+		}
 
-	// Stop RPC server
-	if d.rpcServer != nil {
-		d.rpcServer.Stop()
-	}
+		// Stop RPC server and HTTP gateway
+		if d.rpcServer != nil {
+			d.rpcServer.Stop()
+		}
+		if d.gateway != nil {
+			d.gateway.Stop()
+		}
 
-	// Close database
-	if d.db != nil {
-		d.db.Close()
-	}
+		// Close database
+		if d.db != nil {
+			d.db.Close()
+		}
 
-	// Stop the system tray
-	d.tray.Stop()
+		// Stop the system tray
+		d.tray.Stop()
+	})
 	return nil
 }
 
@@ -125,70 +189,153 @@ func (d *Daemon) StartTray() {
 func (d *Daemon) run() {
 	d.display.ShowInfo("Daemon monitoring started")
 
-	// Calculate time until next 5-minute interval
-	now := time.Now()
-	// Round up to next 5-minute mark
-	nextInterval := now.Truncate(5 * time.Minute)
-	if nextInterval.Before(now) || nextInterval.Equal(now) {
-		nextInterval = nextInterval.Add(5 * time.Minute)
+	// The scheduler is restarted on the new grid when the interval changes
+	for {
+		sched := scheduler.New(scheduler.NewRealClock(), d.sampleInterval(), &activityRecorder{d: d})
+		
+		next := sched.NextSlot(time.Now())
+		d.display.ShowInfo(fmt.Sprintf("Waiting until next %s interval: %s", sched.Interval(), next.Format("15:04:05")))
+		
+		ctx, cancel := context.WithCancel(d.ctx)
+		done := make(chan struct{})
+		go func() {
+			sched.Run(ctx)
+			close(done)
+		}()
+		
+		select {
+		case <-d.ctx.Done():
+			cancel()
+			<-done
+			d.display.ShowInfo("Daemon shutting down...")
+			return
+		case <-d.resched:
+			cancel()
+			<-done
+		}
+	}
+}
+
+// configChanged applies changed settings to tracking and notifications.
+// Settings used by the services are applied by the services themselves.
+func (d *Daemon) configChanged(old, new *config.Config) {
+	if old.NotificationEnabled != new.NotificationEnabled || old.NotificationSound != new.NotificationSound {
+		d.tray.SetNotificationSettings(new.NotificationEnabled, new.NotificationSound)
+	}
+
+	if old.SampleInterval != new.SampleInterval {
+		d.display.ShowInfo("Sample interval changed to " + new.SampleInterval.String())
+		select {
+		case d.resched <- struct{}{}:
+		default:
+		}
+	}
+
+	if old.HTTPPort != new.HTTPPort && d.gateway != nil {
+		d.gateway.Stop()
+		d.startGateway(new.HTTPPort)
+	}
+
+	if d.services != nil && (old.ForegroundProvider != new.ForegroundProvider || old.ForegroundCommand != new.ForegroundCommand) {
+		provider, err := foreground.New(new)
+		if err != nil {
+			log.Printf("Failed to create foreground provider: %v, keeping the current one", err)
+			return
+		}
+		d.services.Activity.SetProvider(provider)
+		d.display.ShowInfo("Foreground provider changed to " + provider.Name())
+	}
+}
+
+// startGateway starts the HTTP gateway on the port, unless it is 0
+func (d *Daemon) startGateway(port int) {
+	if d.gateway == nil || port == 0 {
+		return
+	}
+	if err := d.gateway.Start(port); err != nil {
+		log.Printf("Failed to start HTTP gateway: %v", err)
+		return
+	}
+	d.display.ShowInfo("HTTP gateway listening on http://" + d.gateway.Addr())
+}
+
+// activityRecorder records activity for each scheduler slot
+type activityRecorder struct {
+	d *Daemon
+}
+
+// Tick records activity with app name for the slot
+func (r *activityRecorder) Tick(slot time.Time, interval time.Duration) {
+	d := r.d
+	if d.services == nil {
+		return
 	}
-	waitDuration := nextInterval.Sub(now)
-	
-	d.display.ShowInfo("Waiting until next 5-minute interval: " + nextInterval.Format("15:04:05"))
 	
-	// Wait until the next 5-minute interval
-	timer := time.NewTimer(waitDuration)
-	defer timer.Stop()
+	// While paused, only record that the slot was paused
+	if d.services.Pause.IsPaused(slot) {
+		if err := d.services.Activity.RecordPaused(slot, interval); err != nil {
+			log.Printf("Failed to record paused interval: %v", err)
+			return
+		}
+		d.display.ShowInfo("Tracking paused at " + slot.Format("15:04:05"))
+		return
+	}
 	
-	select {
-	case <-d.ctx.Done():
-		d.display.ShowInfo("Daemon shutting down...")
+	// Sessions and gamification pick the sample up from the event bus
+	if err := d.services.Activity.RecordSample(slot, interval); err != nil {
+		log.Printf("Failed to record activity: %v", err)
+		return
+	}
+	d.display.ShowInfo("Activity recorded at " + slot.Format("15:04:05"))
+}
+
+// Gap records the period in which the machine was suspended
+func (r *activityRecorder) Gap(from, to time.Time) {
+	d := r.d
+	if d.services == nil {
 		return
-	case <-timer.C:
-		// Record first activity at the aligned time with app name
-		if d.services != nil {
-			if err := d.services.Activity.RecordActivityWithApp(); err != nil {
-				log.Printf("Failed to record activity: %v", err)
-			} else {
-				d.display.ShowInfo("Activity recorded at " + time.Now().Format("15:04:05"))
-			}
-		}
 	}
 	
-	// Now start regular 5-minute ticker
-	activityTicker := time.NewTicker(5 * time.Minute)
-	defer activityTicker.Stop()
+	if err := d.services.Activity.RecordSuspended(from, to); err != nil {
+		log.Printf("Failed to record suspended interval: %v", err)
+		return
+	}
+	d.display.ShowInfo(fmt.Sprintf("Resumed after suspend (%s - %s)", from.Format("15:04"), to.Format("15:04")))
+}
 
-	for {
-		select {
-		case <-d.ctx.Done():
-			d.display.ShowInfo("Daemon shutting down...")
-			return
-		case <-activityTicker.C:
-			// Record activity with app name
-			if d.services != nil {
-				if err := d.services.Activity.RecordActivityWithApp(); err != nil {
-					log.Printf("Failed to record activity: %v", err)
-				} else {
-					d.display.ShowInfo("Activity recorded at " + time.Now().Format("15:04:05"))
-					
-					// Process gamification based on app usage
-					go func() {
-						if d.services.Gamification != nil {
-							// Get the app name that was just recorded
-							appName, _ := d.services.Activity.GetLastRecordedApp()
-							if appName != "" {
-								// Default user ID for now
-								userID := "default_user"
-								// Process the last 5 minutes of activity with this app
-								if err := d.services.Gamification.ProcessActivity(userID, appName, 5*time.Minute); err != nil {
-									log.Printf("Failed to process gamification: %v", err)
-								}
-							}
-						}
-					}()
-				}
-			}
+// sampleInterval returns the configured sampling interval
+func (d *Daemon) sampleInterval() time.Duration {
+	interval := config.DefaultConfig().SampleInterval.Duration()
+	if d.config != nil {
+		if configured := d.config.Get().SampleInterval.Duration(); configured >= time.Minute {
+			interval = configured
+		} else {
+			log.Printf("Ignoring sample_interval %s: must be at least 1m", configured)
 		}
 	}
+	return interval
+}
+
+// pauseController adapts the pause service for the tray menu
+type pauseController struct {
+	pause *service.PauseService
+}
+
+// IsPaused reports whether tracking is paused
+func (c *pauseController) IsPaused() bool {
+	state, err := c.pause.State()
+	if err != nil {
+		log.Printf("Failed to read pause state: %v", err)
+		return false
+	}
+	return state.Paused
+}
+
+// TogglePause pauses tracking until resumed, or resumes it
+func (c *pauseController) TogglePause() (bool, error) {
+	state, err := c.pause.Toggle()
+	if err != nil {
+		return false, err
+	}
+	return state.Paused, nil
 }
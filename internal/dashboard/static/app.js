@@ -0,0 +1,406 @@
+'use strict';
+
+// Shien dashboard. Reads activity and gamification data from the daemon's
+// HTTP gateway and refreshes whenever the daemon publishes an event.
+
+const MINUTE = 60 * 1000;
+const SVG = 'http://www.w3.org/2000/svg';
+const LEGACY_SAMPLE_SECONDS = 300;
+const STATE_COLORS = { idle: '#8d96a0', suspended: '#d0d7de', paused: '#d29922' };
+const ATTRIBUTES = ['focus', 'productivity', 'creativity', 'stamina', 'knowledge', 'collaboration'];
+
+let token = null;
+let listening = false;
+let refreshTimer = null;
+
+class Unauthorized extends Error {}
+
+// readToken takes the token from the URL fragment, which never reaches the
+// server, and keeps it for the session
+function readToken() {
+  const match = location.hash.match(/token=([0-9A-Za-z]+)/);
+  if (match) {
+    sessionStorage.setItem('shien-token', match[1]);
+    history.replaceState(null, '', location.pathname);
+  }
+  return sessionStorage.getItem('shien-token');
+}
+
+// call invokes a method of the daemon and returns its result
+async function call(method, params = {}) {
+  const resp = await fetch('/api/' + method, {
+    method: 'POST',
+    headers: { 'Authorization': 'Bearer ' + token, 'Content-Type': 'application/json' },
+    body: JSON.stringify(params),
+  });
+  if (resp.status === 401) {
+    throw new Unauthorized();
+  }
+  const body = await resp.json();
+  if (!resp.ok) {
+    throw new Error(body.error ? body.error.message : resp.statusText);
+  }
+  return body;
+}
+
+// refresh reloads every panel
+async function refresh() {
+  const now = new Date();
+  const today = startOfDay(now);
+  const weekStart = new Date(today);
+  weekStart.setDate(weekStart.getDate() - 6);
+
+  try {
+    const [status, logs, details] = await Promise.all([
+      call('get_status'),
+      call('get_activity_logs', { from: weekStart.toISOString(), to: now.toISOString() }),
+      call('get_gamification_details'),
+    ]);
+
+    const week = logs || [];
+    showStatus(status);
+    renderTimeline(week.filter((log) => new Date(log.recorded_at) >= today), today);
+    renderWeekly(week, weekStart);
+    renderApps(week);
+    renderGamification(details);
+    show('content');
+  } catch (err) {
+    if (err instanceof Unauthorized) {
+      showLogin();
+      return;
+    }
+    setStatus('Error: ' + err.message, false);
+  }
+}
+
+// scheduleRefresh refreshes once after a burst of events
+function scheduleRefresh() {
+  clearTimeout(refreshTimer);
+  refreshTimer = setTimeout(refresh, 300);
+}
+
+// listen follows the daemon's events, reconnecting when the stream ends
+async function listen() {
+  const live = document.getElementById('live');
+  for (;;) {
+    try {
+      const resp = await fetch('/api/subscribe', { headers: { 'Authorization': 'Bearer ' + token } });
+      if (resp.status === 401) {
+        listening = false;
+        showLogin();
+        return;
+      }
+      if (resp.ok) {
+        live.classList.add('on');
+        await readEvents(resp.body, (name) => {
+          // Plain messages are events; "subscribed" acknowledges the stream
+          if (name === '') {
+            scheduleRefresh();
+          }
+        });
+      }
+    } catch (err) {
+      // The daemon stopped or restarted; try again below
+    }
+    live.classList.remove('on');
+    await new Promise((resolve) => setTimeout(resolve, 5000));
+  }
+}
+
+// readEvents parses server-sent events from a stream, calling onEvent with
+// the name and data of each
+async function readEvents(body, onEvent) {
+  const reader = body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = '';
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      return;
+    }
+    buffer += value;
+
+    let end;
+    while ((end = buffer.indexOf('\n\n')) >= 0) {
+      const message = buffer.slice(0, end);
+      buffer = buffer.slice(end + 2);
+
+      let name = '';
+      let data = '';
+      for (const line of message.split('\n')) {
+        if (line.startsWith('event: ')) {
+          name = line.slice(7);
+        } else if (line.startsWith('data: ')) {
+          data += line.slice(6);
+        }
+      }
+      onEvent(name, data ? JSON.parse(data) : null);
+    }
+  }
+}
+
+// showStatus shows whether tracking is paused
+function showStatus(status) {
+  if (status.paused) {
+    const until = status.paused_until ? ' until ' + formatTime(new Date(status.paused_until)) : '';
+    setStatus('Paused' + until, true);
+  } else {
+    setStatus('Tracking', false);
+  }
+}
+
+function setStatus(text, paused) {
+  const badge = document.getElementById('status');
+  badge.textContent = text;
+  badge.classList.toggle('paused', paused);
+}
+
+// renderTimeline draws today's records on a 24 hour bar
+function renderTimeline(logs, today) {
+  const container = document.getElementById('timeline');
+  const svg = svgElement('svg', { viewBox: '0 0 1440 32', preserveAspectRatio: 'none', height: 32 });
+  svg.appendChild(svgElement('rect', { x: 0, y: 0, width: 1440, height: 32, class: 'track' }));
+
+  const apps = new Map();
+  let activeMinutes = 0;
+  for (const log of logs) {
+    const start = (new Date(log.recorded_at) - today) / MINUTE;
+    const minutes = logMinutes(log);
+    const active = isActive(log);
+    const color = active ? appColor(appName(log)) : STATE_COLORS[log.state] || STATE_COLORS.idle;
+
+    const rect = svgElement('rect', { x: start, y: 0, width: minutes, height: 32, fill: color });
+    const title = svgElement('title');
+    title.textContent = `${formatTime(new Date(log.recorded_at))} ${active ? appName(log) : log.state}` +
+      (log.window_title ? ` — ${log.window_title}` : '');
+    rect.appendChild(title);
+    svg.appendChild(rect);
+
+    if (active) {
+      activeMinutes += minutes;
+      apps.set(appName(log), (apps.get(appName(log)) || 0) + minutes);
+    }
+  }
+
+  const axis = document.createElement('div');
+  axis.className = 'legend';
+  axis.style.justifyContent = 'space-between';
+  for (let hour = 0; hour <= 24; hour += 3) {
+    const label = document.createElement('small');
+    label.textContent = String(hour).padStart(2, '0') + ':00';
+    axis.appendChild(label);
+  }
+  container.replaceChildren(svg, axis);
+
+  document.getElementById('today-total').textContent = formatDuration(activeMinutes) + ' active';
+  const legend = document.getElementById('timeline-legend');
+  legend.replaceChildren(...sortedEntries(apps).slice(0, 8).map(([name, minutes]) => {
+    const item = document.createElement('span');
+    const swatch = document.createElement('span');
+    swatch.className = 'swatch';
+    swatch.style.background = appColor(name);
+    item.append(swatch, `${name} ${formatDuration(minutes)}`);
+    return item;
+  }));
+}
+
+// renderWeekly draws the active hours of each of the last 7 days
+function renderWeekly(logs, weekStart) {
+  const days = [];
+  for (let i = 0; i < 7; i++) {
+    const day = new Date(weekStart);
+    day.setDate(day.getDate() + i);
+    days.push({ date: day, minutes: 0 });
+  }
+  for (const log of logs.filter(isActive)) {
+    const day = days.find((d) => sameDay(d.date, new Date(log.recorded_at)));
+    if (day) {
+      day.minutes += logMinutes(log);
+    }
+  }
+
+  const height = 140;
+  const scale = Math.max(8 * 60, ...days.map((d) => d.minutes));
+  const svg = svgElement('svg', { viewBox: `0 0 700 ${height + 36}` });
+  days.forEach((day, i) => {
+    const barHeight = (day.minutes / scale) * height;
+    const x = i * 100 + 25;
+    svg.appendChild(svgElement('rect', {
+      x, y: height - barHeight + 16, width: 50, height: barHeight, rx: 3, class: 'fill',
+    }));
+    svg.appendChild(svgText(x + 25, height - barHeight + 10, day.minutes ? formatDuration(day.minutes) : ''));
+    svg.appendChild(svgText(x + 25, height + 32, day.date.toLocaleDateString(undefined, { weekday: 'short' })));
+  });
+  document.getElementById('weekly').replaceChildren(svg);
+}
+
+// renderApps lists the apps used most over the week
+function renderApps(logs) {
+  const apps = new Map();
+  for (const log of logs.filter(isActive)) {
+    apps.set(appName(log), (apps.get(appName(log)) || 0) + logMinutes(log));
+  }
+
+  const entries = sortedEntries(apps).slice(0, 10);
+  const container = document.getElementById('apps');
+  if (entries.length === 0) {
+    container.replaceChildren(empty('No activity recorded'));
+    return;
+  }
+  const most = entries[0][1];
+  container.replaceChildren(...entries.map(([name, minutes]) =>
+    barRow(name, minutes / most, formatDuration(minutes), appColor(name))));
+}
+
+// renderGamification shows the level, attributes and active modifiers
+function renderGamification(details) {
+  const status = details.status;
+  document.getElementById('level').textContent = `Level ${status.level}`;
+  const progress = status.next_level_exp ? status.experience / status.next_level_exp : 0;
+  document.getElementById('experience').replaceChildren(
+    barRow('Experience', progress, `${status.experience}/${status.next_level_exp}`));
+  document.getElementById('attributes').replaceChildren(...ATTRIBUTES.map((name) =>
+    barRow(name[0].toUpperCase() + name.slice(1), status[name] / 100, String(status[name]))));
+
+  const modifiers = details.modifiers || [];
+  const list = document.getElementById('modifiers');
+  if (modifiers.length === 0) {
+    list.replaceChildren(empty('No active modifiers'));
+    return;
+  }
+  list.replaceChildren(...modifiers.map((mod) => {
+    const item = document.createElement('li');
+    const sign = mod.value > 0 ? '+' : '';
+    const until = mod.expires_at ? ` (until ${formatTime(new Date(mod.expires_at))})` : '';
+    item.textContent = `${mod.attribute} ${sign}${mod.value} — ${mod.reason}${until}`;
+    return item;
+  }));
+}
+
+// barRow builds a labelled horizontal bar filled to a fraction
+function barRow(label, fraction, value, color) {
+  const row = document.createElement('div');
+  row.className = 'row';
+  const name = document.createElement('span');
+  name.textContent = label;
+  const bar = document.createElement('div');
+  bar.className = 'bar';
+  const fill = document.createElement('div');
+  fill.style.width = Math.min(100, Math.max(0, fraction * 100)) + '%';
+  if (color) {
+    fill.style.background = color;
+  }
+  bar.appendChild(fill);
+  const amount = document.createElement('span');
+  amount.className = 'value';
+  amount.textContent = value;
+  row.append(name, bar, amount);
+  return row;
+}
+
+function empty(text) {
+  const element = document.createElement('p');
+  element.className = 'empty';
+  element.textContent = text;
+  return element;
+}
+
+function svgElement(name, attributes = {}) {
+  const element = document.createElementNS(SVG, name);
+  for (const [key, value] of Object.entries(attributes)) {
+    element.setAttribute(key, value);
+  }
+  return element;
+}
+
+function svgText(x, y, text) {
+  const element = svgElement('text', { x, y, 'text-anchor': 'middle' });
+  element.textContent = text;
+  return element;
+}
+
+// Records from daemons predating the state column have no state
+function isActive(log) {
+  return !log.state || log.state === 'active';
+}
+
+function appName(log) {
+  return log.app_name || log.raw_app_name || 'Unknown';
+}
+
+function logMinutes(log) {
+  return (log.duration_seconds > 0 ? log.duration_seconds : LEGACY_SAMPLE_SECONDS) / 60;
+}
+
+// appColor gives each app a stable color
+function appColor(name) {
+  let hash = 0;
+  for (const c of name) {
+    hash = (hash * 31 + c.codePointAt(0)) % 360;
+  }
+  return `hsl(${hash}, 55%, 55%)`;
+}
+
+function sortedEntries(map) {
+  return [...map.entries()].sort((a, b) => b[1] - a[1]);
+}
+
+function startOfDay(date) {
+  return new Date(date.getFullYear(), date.getMonth(), date.getDate());
+}
+
+function sameDay(a, b) {
+  return a.toDateString() === b.toDateString();
+}
+
+function formatTime(date) {
+  return date.toLocaleTimeString(undefined, { hour: '2-digit', minute: '2-digit' });
+}
+
+function formatDuration(minutes) {
+  const total = Math.round(minutes);
+  const hours = Math.floor(total / 60);
+  const rest = String(total % 60).padStart(2, '0');
+  return hours > 0 ? `${hours}h ${rest}m` : `${total}m`;
+}
+
+function show(id) {
+  document.getElementById(id).hidden = false;
+}
+
+function showLogin() {
+  sessionStorage.removeItem('shien-token');
+  document.getElementById('content').hidden = true;
+  setStatus('Not connected', false);
+  show('login');
+}
+
+// start loads the dashboard and follows events from then on
+function start() {
+  document.getElementById('login').hidden = true;
+  refresh();
+  if (!listening) {
+    listening = true;
+    listen();
+  }
+}
+
+document.getElementById('login').addEventListener('submit', (event) => {
+  event.preventDefault();
+  token = document.getElementById('token').value.trim();
+  sessionStorage.setItem('shien-token', token);
+  start();
+});
+
+// The timeline moves on even when nothing is recorded
+setInterval(() => {
+  if (token) {
+    refresh();
+  }
+}, MINUTE);
+
+token = readToken();
+if (token) {
+  start();
+} else {
+  showLogin();
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration004_AddWindowDetailsToActivity adds raw window details to activity_logs
+var Migration004_AddWindowDetailsToActivity = Migration{
+	Version:     4,
+	Description: "Add raw app name, window title, document and URL to activity_logs",
+	Up: func(tx *sql.Tx) error {
+		// app_name keeps the normalized category; these columns keep what
+		// was actually in the foreground
+		columns := []string{
+			"raw_app_name TEXT",
+			"window_title TEXT",
+			"document TEXT",
+			"url TEXT",
+		}
+		for _, column := range columns {
+			if _, err := tx.Exec(`ALTER TABLE activity_logs ADD COLUMN ` + column); err != nil {
+				return err
+			}
+		}
+
+		// Add index for queries by raw app name
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_activity_logs_raw_app_name 
+			ON activity_logs(raw_app_name)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration005_AddStateToActivity adds a state column to activity_logs
+var Migration005_AddStateToActivity = Migration{
+	Version:     5,
+	Description: "Add state column to activity_logs",
+	Up: func(tx *sql.Tx) error {
+		// Existing rows were all recorded while the user was active
+		if _, err := tx.Exec(`
+			ALTER TABLE activity_logs 
+			ADD COLUMN state TEXT NOT NULL DEFAULT 'active'
+		`); err != nil {
+			return err
+		}
+
+		// Add index for filtering by state
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_activity_logs_state 
+			ON activity_logs(state, recorded_at)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration006_AddDurationToActivity records how long each activity sample represents
+var Migration006_AddDurationToActivity = Migration{
+	Version:     6,
+	Description: "Add duration_seconds column to activity_logs",
+	Up: func(tx *sql.Tx) error {
+		// Rows recorded so far were sampled every 5 minutes
+		if _, err := tx.Exec(`
+			ALTER TABLE activity_logs 
+			ADD COLUMN duration_seconds INTEGER NOT NULL DEFAULT 300
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration007_Sessions adds the sessions table
+var Migration007_Sessions = Migration{
+	Version:     7,
+	Description: "Add sessions table",
+	Up: func(tx *sql.Tx) error {
+		// Continuous periods of work in one app, merged from activity samples
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS sessions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				app_name TEXT NOT NULL,
+				started_at DATETIME NOT NULL, -- stored in UTC
+				ended_at DATETIME NOT NULL,   -- stored in UTC
+				sample_count INTEGER NOT NULL DEFAULT 0,
+				duration_seconds INTEGER NOT NULL DEFAULT 0
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Index for querying by time range
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_sessions_started_at 
+			ON sessions(started_at, ended_at)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
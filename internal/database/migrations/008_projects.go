@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration008_Projects adds projects, project rules and tags
+var Migration008_Projects = Migration{
+	Version:     8,
+	Description: "Add projects, project rules and tags",
+	Up: func(tx *sql.Tx) error {
+		// Projects (or clients) that time is attributed to
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS projects (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				description TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Rules assigning samples to projects automatically
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS project_rules (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				kind TEXT NOT NULL,      -- 'app', 'title' or 'time'
+				pattern TEXT NOT NULL,   -- app name, title regex or HH:MM-HH:MM
+				priority INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Free-form tags
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Tags attached to activity records
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS activity_tags (
+				activity_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				
+				PRIMARY KEY (activity_id, tag_id),
+				FOREIGN KEY (activity_id) REFERENCES activity_logs(id) ON DELETE CASCADE,
+				FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Project of each activity record (NULL when unassigned)
+		if _, err := tx.Exec(`
+			ALTER TABLE activity_logs ADD COLUMN project_id INTEGER REFERENCES projects(id)
+		`); err != nil {
+			return err
+		}
+
+		// Index for project reports
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_activity_logs_project 
+			ON activity_logs(project_id, recorded_at)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
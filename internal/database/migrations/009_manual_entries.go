@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration009_ManualEntries adds the manual_entries table
+var Migration009_ManualEntries = Migration{
+	Version:     9,
+	Description: "Add manual_entries table",
+	Up: func(tx *sql.Tx) error {
+		// Work done away from the computer, entered by the user
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS manual_entries (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				started_at DATETIME NOT NULL, -- stored in UTC
+				ended_at DATETIME NOT NULL,   -- stored in UTC
+				category TEXT NOT NULL,       -- e.g. 'Meeting', 'Reading'
+				note TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`); err != nil {
+			return err
+		}
+
+		// Index for querying by time range
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_manual_entries_started_at 
+			ON manual_entries(started_at, ended_at)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"database/sql"
+)
+
+// Migration010_Pauses adds the pauses table
+var Migration010_Pauses = Migration{
+	Version:     10,
+	Description: "Add pauses table",
+	Up: func(tx *sql.Tx) error {
+		// Periods in which tracking was paused by the user.
+		// An open pause (ended_at IS NULL) survives daemon restarts.
+		if _, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS pauses (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				started_at DATETIME NOT NULL, -- stored in UTC
+				resume_at DATETIME,           -- automatic resume time, NULL for until resumed
+				ended_at DATETIME             -- NULL while paused
+			)
+		`); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_pauses_started_at 
+			ON pauses(started_at, ended_at)
+		`); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
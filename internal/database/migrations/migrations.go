@@ -17,7 +17,14 @@ func All() []Migration {
 		Migration001ActivityLogs,
 		Migration002_Gamification,
 		Migration003_AddAppNameToActivity,
+		Migration004_AddWindowDetailsToActivity,
+		Migration005_AddStateToActivity,
+		Migration006_AddDurationToActivity,
+		Migration007_Sessions,
+		Migration008_Projects,
+		Migration009_ManualEntries,
+		Migration010_Pauses,
 		// Future migrations will be added here:
-		// Migration004AddFieldToActivityLogs,
+		// Migration011AddFieldToActivityLogs,
 	}
 }
\ No newline at end of file
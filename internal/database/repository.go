@@ -9,6 +9,10 @@ type Repository struct {
 	db           *DB
 	activity     *repository.ActivityRepo
 	gamification *repository.GamificationRepo
+	sessions     *repository.SessionRepo
+	projects     *repository.ProjectRepo
+	manual       *repository.ManualEntryRepo
+	pauses       *repository.PauseRepo
 }
 
 // NewRepository creates a new repository manager
@@ -17,6 +21,10 @@ func NewRepository(db *DB) *Repository {
 		db:           db,
 		activity:     repository.NewActivityRepo(db.Conn()),
 		gamification: repository.NewGamificationRepo(db.Conn()),
+		sessions:     repository.NewSessionRepo(db.Conn()),
+		projects:     repository.NewProjectRepo(db.Conn()),
+		manual:       repository.NewManualEntryRepo(db.Conn()),
+		pauses:       repository.NewPauseRepo(db.Conn()),
 	}
 }
 
@@ -29,3 +37,23 @@ func (r *Repository) Activity() *repository.ActivityRepo {
 func (r *Repository) Gamification() *repository.GamificationRepo {
 	return r.gamification
 }
+
+// Sessions returns the session repository
+func (r *Repository) Sessions() *repository.SessionRepo {
+	return r.sessions
+}
+
+// Projects returns the project repository
+func (r *Repository) Projects() *repository.ProjectRepo {
+	return r.projects
+}
+
+// ManualEntries returns the manual entry repository
+func (r *Repository) ManualEntries() *repository.ManualEntryRepo {
+	return r.manual
+}
+
+// Pauses returns the pause repository
+func (r *Repository) Pauses() *repository.PauseRepo {
+	return r.pauses
+}
@@ -7,13 +7,69 @@ import (
 	"shien/internal/utils"
 )
 
+// LegacySampleDuration is the duration of records that don't carry one
+// (all records were sampled every 5 minutes before the interval was configurable)
+const LegacySampleDuration = 5 * time.Minute
+
+// Record sources
+const (
+	SourceSampled = "sampled" // Recorded by the daemon
+	SourceManual  = "manual"  // Derived from a manual entry
+)
+
+// Activity states
+const (
+	StateActive    = "active"    // The user was at the computer
+	StateIdle      = "idle"      // No input for longer than the idle threshold
+	StateSuspended = "suspended" // The machine was asleep; no samples were taken
+	StatePaused    = "paused"    // Tracking was paused by the user
+)
+
 // ActivityLog represents an activity record
 type ActivityLog struct {
-	ID         int64          `json:"id"`
-	RecordedAt utils.UTCTime `json:"recorded_at"`
-	AppName    *string        `json:"app_name,omitempty"`
+	ID          int64         `json:"id"`
+	RecordedAt  utils.UTCTime `json:"recorded_at"`
+	State       string        `json:"state"`
+	DurationSec int           `json:"duration_seconds"`
+	AppName     *string       `json:"app_name,omitempty"`     // Normalized category
+	RawAppName  *string       `json:"raw_app_name,omitempty"` // Name reported by the OS
+	WindowTitle *string       `json:"window_title,omitempty"`
+	Document    *string       `json:"document,omitempty"`
+	URL         *string       `json:"url,omitempty"`
+	ProjectID   *int64        `json:"project_id,omitempty"`
+	Source      string        `json:"source,omitempty"` // SourceSampled or SourceManual (empty means sampled)
 }
 
+// IsActive reports whether the record counts as active time
+func (l ActivityLog) IsActive() bool {
+	// Records from daemons predating the state column have no state
+	return l.State == "" || l.State == StateActive
+}
+
+// Duration returns the time the record represents
+func (l ActivityLog) Duration() time.Duration {
+	if l.DurationSec <= 0 {
+		return LegacySampleDuration
+	}
+	return time.Duration(l.DurationSec) * time.Second
+}
+
+// Minutes returns the time the record represents in minutes
+func (l ActivityLog) Minutes() float64 {
+	return l.Duration().Minutes()
+}
+
+// WindowDetails holds the raw foreground window information for a record
+type WindowDetails struct {
+	RawAppName  string
+	WindowTitle string
+	Document    string
+	URL         string
+}
+
+// activityLogColumns lists the columns scanned by scanActivityLogs
+const activityLogColumns = `id, recorded_at, state, duration_seconds, app_name, raw_app_name, window_title, document, url, project_id`
+
 // ActivityRepo implements ActivityRepository
 type ActivityRepo struct {
 	conn *sql.DB
@@ -24,33 +80,74 @@ func NewActivityRepo(conn *sql.DB) *ActivityRepo {
 	return &ActivityRepo{conn: conn}
 }
 
-// RecordActivity records that the app is running at the current time
-func (r *ActivityRepo) RecordActivity() error {
+// RecordActivity records that the app is running at the current time.
+// duration is the time the record represents (the sampling interval).
+func (r *ActivityRepo) RecordActivity(duration time.Duration) error {
 	// Round to minute precision
 	now := utils.Now().TruncateToMinute()
 	
 	// Try to insert, ignore if already exists for this minute
 	_, err := r.conn.Exec(`
-		INSERT OR IGNORE INTO activity_logs (recorded_at) 
-		VALUES (?)
-	`, now)
+		INSERT OR IGNORE INTO activity_logs (recorded_at, duration_seconds) 
+		VALUES (?, ?)
+	`, now, durationSeconds(duration))
 	
 	return err
 }
 
 // RecordActivityWithApp records activity with the application name
-func (r *ActivityRepo) RecordActivityWithApp(appName string) error {
+func (r *ActivityRepo) RecordActivityWithApp(appName string, duration time.Duration) error {
 	// Round to minute precision
 	now := utils.Now().TruncateToMinute()
 	
 	// Try to insert, ignore if already exists for this minute
 	// If it exists, update the app_name
 	_, err := r.conn.Exec(`
-		INSERT INTO activity_logs (recorded_at, app_name) 
-		VALUES (?, ?)
+		INSERT INTO activity_logs (recorded_at, app_name, duration_seconds) 
+		VALUES (?, ?, ?)
 		ON CONFLICT(strftime('%Y-%m-%d %H:%M', recorded_at))
-		DO UPDATE SET app_name = excluded.app_name
-	`, now, appName)
+		DO UPDATE SET app_name = excluded.app_name,
+		              duration_seconds = excluded.duration_seconds
+	`, now, appName, durationSeconds(duration))
+	
+	return err
+}
+
+// Sample is a single activity record to store
+type Sample struct {
+	RecordedAt time.Time     // When the sample was taken (start of a suspended period)
+	State      string        // StateActive, StateIdle or StateSuspended
+	Duration   time.Duration // Time the record represents
+	AppName    string        // Normalized category, empty when unknown
+	Window     WindowDetails // Raw window details, empty when not active
+	ProjectID  *int64        // Project assigned by the rules, nil when none matched
+}
+
+// RecordSample stores a sample, replacing any record for the same minute
+func (r *ActivityRepo) RecordSample(sample Sample) error {
+	// Round to minute precision
+	at := utils.NewUTCTime(sample.RecordedAt).TruncateToMinute()
+	
+	state := sample.State
+	if state == "" {
+		state = StateActive
+	}
+	
+	_, err := r.conn.Exec(`
+		INSERT INTO activity_logs (recorded_at, state, duration_seconds, app_name, raw_app_name, window_title, document, url, project_id) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(strftime('%Y-%m-%d %H:%M', recorded_at))
+		DO UPDATE SET state = excluded.state,
+		              duration_seconds = excluded.duration_seconds,
+		              app_name = excluded.app_name,
+		              raw_app_name = excluded.raw_app_name,
+		              window_title = excluded.window_title,
+		              document = excluded.document,
+		              url = excluded.url,
+		              project_id = excluded.project_id
+	`, at, state, durationSeconds(sample.Duration), nullString(sample.AppName),
+		nullString(sample.Window.RawAppName), nullString(sample.Window.WindowTitle),
+		nullString(sample.Window.Document), nullString(sample.Window.URL), sample.ProjectID)
 	
 	return err
 }
@@ -58,7 +155,7 @@ func (r *ActivityRepo) RecordActivityWithApp(appName string) error {
 // GetActivityLogs returns activity logs within a time range
 func (r *ActivityRepo) GetActivityLogs(from, to time.Time) ([]ActivityLog, error) {
 	rows, err := r.conn.Query(`
-		SELECT id, recorded_at, app_name
+		SELECT `+activityLogColumns+`
 		FROM activity_logs 
 		WHERE recorded_at >= ? 
 		  AND recorded_at <= ?
@@ -69,10 +166,16 @@ func (r *ActivityRepo) GetActivityLogs(from, to time.Time) ([]ActivityLog, error
 	}
 	defer rows.Close()
 	
+	return scanActivityLogs(rows)
+}
+
+// scanActivityLogs reads rows selected with activityLogColumns
+func scanActivityLogs(rows *sql.Rows) ([]ActivityLog, error) {
 	var logs []ActivityLog
 	for rows.Next() {
 		var log ActivityLog
-		err := rows.Scan(&log.ID, &log.RecordedAt, &log.AppName)
+		err := rows.Scan(&log.ID, &log.RecordedAt, &log.State, &log.DurationSec, &log.AppName,
+			&log.RawAppName, &log.WindowTitle, &log.Document, &log.URL, &log.ProjectID)
 		if err != nil {
 			return nil, err
 		}
@@ -82,27 +185,15 @@ func (r *ActivityRepo) GetActivityLogs(from, to time.Time) ([]ActivityLog, error
 	return logs, rows.Err()
 }
 
-// GetActivitySummary returns a summary of activity for a given date
-func (r *ActivityRepo) GetActivitySummary(date time.Time) (map[string]interface{}, error) {
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-	
-	// Count total activity records (each represents 5 minutes)
-	var count int
-	err := r.conn.QueryRow(`
-		SELECT COUNT(*) 
-		FROM activity_logs 
-		WHERE recorded_at >= ? 
-		  AND recorded_at < ?
-	`, utils.ToUTC(startOfDay), utils.ToUTC(endOfDay)).Scan(&count)
-	
-	if err != nil {
-		return nil, err
+// durationSeconds converts a sample duration for storage
+func durationSeconds(duration time.Duration) int {
+	if duration <= 0 {
+		return int(LegacySampleDuration.Seconds())
 	}
-	
-	return map[string]interface{}{
-		"date":           date.Format("2006-01-02"),
-		"activity_count": count,
-		"minutes_active": count * 5, // Each record represents 5 minutes
-	}, nil
-}
\ No newline at end of file
+	return int(duration.Round(time.Second).Seconds())
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -6,60 +6,150 @@ import (
 	"shien/internal/utils"
 )
 
-// GetAppUsageSummary returns app usage statistics for a given time range
-func (r *ActivityRepo) GetAppUsageSummary(from, to time.Time) (map[string]int, error) {
+// GetRecentAppActivity returns the most recent app activities
+func (r *ActivityRepo) GetRecentAppActivity(limit int) ([]ActivityLog, error) {
+	rows, err := r.conn.Query(`
+		SELECT `+activityLogColumns+`
+		FROM activity_logs 
+		WHERE app_name IS NOT NULL
+		ORDER BY recorded_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	return scanActivityLogs(rows)
+}
+
+// RawAppUsage is the active time recorded for a raw app name and category
+type RawAppUsage struct {
+	RawAppName string        `json:"raw_app_name"`
+	AppName    string        `json:"app_name"` // Category stored with the records
+	Minutes    int           `json:"minutes"`
+	LastSeen   utils.UTCTime `json:"last_seen"`
+}
+
+// GetRawAppUsage returns the raw app names seen in a time range with their
+// stored categories. Records from before raw names were kept use the category.
+func (r *ActivityRepo) GetRawAppUsage(from, to time.Time) ([]RawAppUsage, error) {
 	rows, err := r.conn.Query(`
-		SELECT app_name, COUNT(*) as minutes
+		SELECT COALESCE(raw_app_name, app_name) as raw, app_name,
+		       SUM(duration_seconds) as seconds, MAX(recorded_at)
 		FROM activity_logs 
 		WHERE recorded_at >= ? 
 		  AND recorded_at <= ?
 		  AND app_name IS NOT NULL
-		GROUP BY app_name
-		ORDER BY minutes DESC
-	`, utils.ToUTC(from), utils.ToUTC(to))
+		  AND state = ?
+		GROUP BY raw, app_name
+		ORDER BY seconds DESC
+	`, utils.ToUTC(from), utils.ToUTC(to), StateActive)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	
-	usage := make(map[string]int)
+	var usage []RawAppUsage
 	for rows.Next() {
-		var appName string
-		var minutes int
-		err := rows.Scan(&appName, &minutes)
-		if err != nil {
+		var entry RawAppUsage
+		var seconds int
+		var lastSeen string
+		if err := rows.Scan(&entry.RawAppName, &entry.AppName, &seconds, &lastSeen); err != nil {
 			return nil, err
 		}
-		// Each record represents 5 minutes
-		usage[appName] = minutes * 5
+		// MAX() returns the stored text, so parse it like a column
+		if err := entry.LastSeen.Scan(lastSeen); err != nil {
+			return nil, err
+		}
+		entry.Minutes = seconds / 60
+		usage = append(usage, entry)
 	}
 	
 	return usage, rows.Err()
 }
 
-// GetRecentAppActivity returns the most recent app activities
-func (r *ActivityRepo) GetRecentAppActivity(limit int) ([]ActivityLog, error) {
+// CategoryChange moves the records of a raw app name from one category to another
+type CategoryChange struct {
+	RawAppName string `json:"raw_app_name"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Rows       int64  `json:"rows"`
+}
+
+// GetRawAppCategories returns the number of records per raw app name and
+// stored category in a time range. Records without a raw name are skipped.
+func (r *ActivityRepo) GetRawAppCategories(from, to time.Time) ([]CategoryChange, error) {
 	rows, err := r.conn.Query(`
-		SELECT id, recorded_at, app_name
+		SELECT raw_app_name, COALESCE(app_name, ''), COUNT(*)
 		FROM activity_logs 
-		WHERE app_name IS NOT NULL
-		ORDER BY recorded_at DESC
-		LIMIT ?
-	`, limit)
+		WHERE recorded_at >= ? 
+		  AND recorded_at <= ?
+		  AND raw_app_name IS NOT NULL
+		GROUP BY raw_app_name, app_name
+	`, utils.ToUTC(from), utils.ToUTC(to))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	
-	var logs []ActivityLog
+	var categories []CategoryChange
 	for rows.Next() {
-		var log ActivityLog
-		err := rows.Scan(&log.ID, &log.RecordedAt, &log.AppName)
-		if err != nil {
+		var entry CategoryChange
+		if err := rows.Scan(&entry.RawAppName, &entry.From, &entry.Rows); err != nil {
 			return nil, err
 		}
-		logs = append(logs, log)
+		entry.To = entry.From
+		categories = append(categories, entry)
+	}
+	
+	return categories, rows.Err()
+}
+
+// CountRecordsWithoutRawName returns the number of app records in a time range
+// stored before raw app names were kept
+func (r *ActivityRepo) CountRecordsWithoutRawName(from, to time.Time) (int64, error) {
+	var count int64
+	err := r.conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM activity_logs 
+		WHERE recorded_at >= ? 
+		  AND recorded_at <= ?
+		  AND raw_app_name IS NULL
+		  AND app_name IS NOT NULL
+	`, utils.ToUTC(from), utils.ToUTC(to)).Scan(&count)
+	
+	return count, err
+}
+
+// ApplyCategoryChanges updates the category of records in a time range in a
+// single transaction and returns the number of records changed
+func (r *ActivityRepo) ApplyCategoryChanges(from, to time.Time, changes []CategoryChange) (int64, error) {
+	tx, err := r.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	
+	var total int64
+	for _, change := range changes {
+		result, err := tx.Exec(`
+			UPDATE activity_logs SET app_name = ?
+			WHERE recorded_at >= ? 
+			  AND recorded_at <= ?
+			  AND raw_app_name = ?
+			  AND COALESCE(app_name, '') = ?
+		`, nullString(change.To), utils.ToUTC(from), utils.ToUTC(to), change.RawAppName, change.From)
+		if err != nil {
+			return 0, err
+		}
+		
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += affected
 	}
 	
-	return logs, rows.Err()
-}
\ No newline at end of file
+	return total, tx.Commit()
+}
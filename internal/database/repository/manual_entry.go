@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"shien/internal/utils"
+)
+
+// ManualEntry is activity entered by the user, e.g. a meeting away from the computer
+type ManualEntry struct {
+	ID        int64         `json:"id"`
+	StartedAt utils.UTCTime `json:"started_at"`
+	EndedAt   utils.UTCTime `json:"ended_at"`
+	Category  string        `json:"category"`
+	Note      string        `json:"note,omitempty"`
+}
+
+// Duration returns the length of the entry
+func (e ManualEntry) Duration() time.Duration {
+	return e.EndedAt.Sub(e.StartedAt.Time)
+}
+
+// ManualEntryRepo handles manual entry persistence
+type ManualEntryRepo struct {
+	conn *sql.DB
+}
+
+// NewManualEntryRepo creates a new manual entry repository
+func NewManualEntryRepo(conn *sql.DB) *ManualEntryRepo {
+	return &ManualEntryRepo{conn: conn}
+}
+
+// AddEntry stores a manual entry and sets its ID
+func (r *ManualEntryRepo) AddEntry(entry *ManualEntry) error {
+	result, err := r.conn.Exec(`
+		INSERT INTO manual_entries (started_at, ended_at, category, note)
+		VALUES (?, ?, ?, ?)
+	`, entry.StartedAt, entry.EndedAt, entry.Category, nullString(entry.Note))
+	if err != nil {
+		return err
+	}
+
+	entry.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetEntries returns the entries overlapping the time range, oldest first
+func (r *ManualEntryRepo) GetEntries(from, to time.Time) ([]ManualEntry, error) {
+	rows, err := r.conn.Query(`
+		SELECT id, started_at, ended_at, category, COALESCE(note, '')
+		FROM manual_entries
+		WHERE ended_at > ?
+		  AND started_at < ?
+		ORDER BY started_at ASC
+	`, utils.ToUTC(from), utils.ToUTC(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ManualEntry
+	for rows.Next() {
+		var entry ManualEntry
+		if err := rows.Scan(&entry.ID, &entry.StartedAt, &entry.EndedAt, &entry.Category, &entry.Note); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"shien/internal/utils"
+)
+
+// Pause is a period in which tracking was paused
+type Pause struct {
+	ID        int64          `json:"id"`
+	StartedAt utils.UTCTime  `json:"started_at"`
+	ResumeAt  *utils.UTCTime `json:"resume_at,omitempty"` // nil when paused until resumed
+	EndedAt   *utils.UTCTime `json:"ended_at,omitempty"`  // nil while paused
+}
+
+// setOptional sets the nullable times that were scanned (NULL scans as zero)
+func (p *Pause) setOptional(resumeAt, endedAt utils.UTCTime) {
+	if !resumeAt.IsZero() {
+		p.ResumeAt = &resumeAt
+	}
+	if !endedAt.IsZero() {
+		p.EndedAt = &endedAt
+	}
+}
+
+// roundUpToMinute returns the first whole minute at or after t. Times are
+// stored with minute precision, and a truncated resume time would end the
+// pause up to a minute early.
+func roundUpToMinute(t time.Time) time.Time {
+	rounded := t.Truncate(time.Minute)
+	if rounded.Before(t) {
+		rounded = rounded.Add(time.Minute)
+	}
+	return rounded
+}
+
+// pauseColumns lists the columns scanned into a Pause
+const pauseColumns = `id, started_at, resume_at, ended_at`
+
+// PauseRepo handles pause persistence
+type PauseRepo struct {
+	conn *sql.DB
+}
+
+// NewPauseRepo creates a new pause repository
+func NewPauseRepo(conn *sql.DB) *PauseRepo {
+	return &PauseRepo{conn: conn}
+}
+
+// GetOpenPause returns the pause in effect, or nil if tracking is not paused
+func (r *PauseRepo) GetOpenPause() (*Pause, error) {
+	var pause Pause
+	var resumeAt, endedAt utils.UTCTime
+	err := r.conn.QueryRow(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE ended_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`).Scan(&pause.ID, &pause.StartedAt, &resumeAt, &endedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pause.setOptional(resumeAt, endedAt)
+	return &pause, nil
+}
+
+// StartPause opens a new pause. A zero resumeAt pauses until resumed.
+func (r *PauseRepo) StartPause(startedAt, resumeAt time.Time) (*Pause, error) {
+	pause := &Pause{StartedAt: utils.NewUTCTime(startedAt)}
+	var resume interface{}
+	if !resumeAt.IsZero() {
+		at := utils.NewUTCTime(roundUpToMinute(resumeAt))
+		pause.ResumeAt = &at
+		resume = at
+	}
+
+	result, err := r.conn.Exec(`
+		INSERT INTO pauses (started_at, resume_at)
+		VALUES (?, ?)
+	`, pause.StartedAt, resume)
+	if err != nil {
+		return nil, err
+	}
+
+	pause.ID, err = result.LastInsertId()
+	return pause, err
+}
+
+// EndPause closes a pause
+func (r *PauseRepo) EndPause(id int64, endedAt time.Time) error {
+	_, err := r.conn.Exec(`
+		UPDATE pauses SET ended_at = ?
+		WHERE id = ?
+	`, utils.NewUTCTime(endedAt), id)
+
+	return err
+}
+
+// GetPauses returns the pauses overlapping a time range, oldest first
+func (r *PauseRepo) GetPauses(from, to time.Time) ([]Pause, error) {
+	rows, err := r.conn.Query(`
+		SELECT `+pauseColumns+`
+		FROM pauses
+		WHERE (ended_at IS NULL OR ended_at >= ?)
+		  AND started_at <= ?
+		ORDER BY started_at ASC
+	`, utils.ToUTC(from), utils.ToUTC(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []Pause
+	for rows.Next() {
+		var pause Pause
+		var resumeAt, endedAt utils.UTCTime
+		if err := rows.Scan(&pause.ID, &pause.StartedAt, &resumeAt, &endedAt); err != nil {
+			return nil, err
+		}
+		pause.setOptional(resumeAt, endedAt)
+		pauses = append(pauses, pause)
+	}
+
+	return pauses, rows.Err()
+}
+
+// SetResumeAt changes when a pause ends automatically. A zero resumeAt pauses until resumed.
+func (r *PauseRepo) SetResumeAt(id int64, resumeAt time.Time) error {
+	var resume interface{}
+	if !resumeAt.IsZero() {
+		resume = utils.NewUTCTime(roundUpToMinute(resumeAt))
+	}
+
+	_, err := r.conn.Exec(`
+		UPDATE pauses SET resume_at = ?
+		WHERE id = ?
+	`, resume, id)
+
+	return err
+}
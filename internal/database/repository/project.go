@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"shien/internal/utils"
+)
+
+// Project is a project or client that time is attributed to
+type Project struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProjectRule assigns matching samples to a project
+type ProjectRule struct {
+	ID          int64  `json:"id"`
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name,omitempty"`
+	Kind        string `json:"kind"`    // "app", "title" or "time"
+	Pattern     string `json:"pattern"` // App name, title regex or HH:MM-HH:MM
+	Priority    int    `json:"priority"`
+}
+
+// ProjectUsage is the active time attributed to a project
+type ProjectUsage struct {
+	ProjectID *int64 `json:"project_id,omitempty"` // nil for unassigned time
+	Name      string `json:"name"`
+	Minutes   int    `json:"minutes"`
+}
+
+// ProjectRepo handles project, rule and tag persistence
+type ProjectRepo struct {
+	conn *sql.DB
+}
+
+// NewProjectRepo creates a new project repository
+func NewProjectRepo(conn *sql.DB) *ProjectRepo {
+	return &ProjectRepo{conn: conn}
+}
+
+// CreateProject creates a new project
+func (r *ProjectRepo) CreateProject(name, description string) (*Project, error) {
+	now := time.Now()
+	result, err := r.conn.Exec(`
+		INSERT INTO projects (name, description, created_at)
+		VALUES (?, ?, ?)
+	`, name, nullString(description), utils.NewUTCTime(now))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Project{ID: id, Name: name, Description: description, CreatedAt: now}, nil
+}
+
+// GetProjects returns all projects ordered by name
+func (r *ProjectRepo) GetProjects() ([]Project, error) {
+	rows, err := r.conn.Query(`
+		SELECT id, name, COALESCE(description, ''), created_at
+		FROM projects
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		var project Project
+		var createdAt utils.UTCTime
+		if err := rows.Scan(&project.ID, &project.Name, &project.Description, &createdAt); err != nil {
+			return nil, err
+		}
+		project.CreatedAt = createdAt.Time
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+// GetProjectByName returns the project with the given name, or nil if there is none
+func (r *ProjectRepo) GetProjectByName(name string) (*Project, error) {
+	var project Project
+	var createdAt utils.UTCTime
+	err := r.conn.QueryRow(`
+		SELECT id, name, COALESCE(description, ''), created_at
+		FROM projects
+		WHERE name = ?
+	`, name).Scan(&project.ID, &project.Name, &project.Description, &createdAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	project.CreatedAt = createdAt.Time
+	return &project, nil
+}
+
+// AddRule stores a new rule and sets its ID
+func (r *ProjectRepo) AddRule(rule *ProjectRule) error {
+	result, err := r.conn.Exec(`
+		INSERT INTO project_rules (project_id, kind, pattern, priority)
+		VALUES (?, ?, ?, ?)
+	`, rule.ProjectID, rule.Kind, rule.Pattern, rule.Priority)
+	if err != nil {
+		return err
+	}
+
+	rule.ID, err = result.LastInsertId()
+	return err
+}
+
+// RemoveRule deletes a rule, reporting whether it existed
+func (r *ProjectRepo) RemoveRule(id int64) (bool, error) {
+	result, err := r.conn.Exec(`DELETE FROM project_rules WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetRules returns all rules in the order they are created
+func (r *ProjectRepo) GetRules() ([]ProjectRule, error) {
+	rows, err := r.conn.Query(`
+		SELECT r.id, r.project_id, p.name, r.kind, r.pattern, r.priority
+		FROM project_rules r
+		JOIN projects p ON p.id = r.project_id
+		ORDER BY r.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ProjectRule
+	for rows.Next() {
+		var rule ProjectRule
+		if err := rows.Scan(&rule.ID, &rule.ProjectID, &rule.ProjectName, &rule.Kind, &rule.Pattern, &rule.Priority); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// AssignProject sets the project of every record in the time range.
+// A nil projectID clears the assignment. It returns the number of records changed.
+func (r *ProjectRepo) AssignProject(from, to time.Time, projectID *int64) (int64, error) {
+	result, err := r.conn.Exec(`
+		UPDATE activity_logs SET project_id = ?
+		WHERE recorded_at >= ?
+		  AND recorded_at < ?
+	`, projectID, utils.ToUTC(from), utils.ToUTC(to))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// TagActivity attaches a tag to every record in the time range, creating the
+// tag if needed. It returns the number of records tagged.
+func (r *ProjectRepo) TagActivity(from, to time.Time, tag string) (int64, error) {
+	tx, err := r.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO activity_tags (activity_id, tag_id)
+		SELECT a.id, t.id
+		FROM activity_logs a, tags t
+		WHERE t.name = ?
+		  AND a.recorded_at >= ?
+		  AND a.recorded_at < ?
+	`, tag, utils.ToUTC(from), utils.ToUTC(to))
+	if err != nil {
+		return 0, err
+	}
+
+	tagged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return tagged, tx.Commit()
+}
+
+// GetProjectReport returns the active time per project in the time range,
+// including unassigned time
+func (r *ProjectRepo) GetProjectReport(from, to time.Time) ([]ProjectUsage, error) {
+	rows, err := r.conn.Query(`
+		SELECT a.project_id, COALESCE(p.name, ''), SUM(a.duration_seconds) as seconds
+		FROM activity_logs a
+		LEFT JOIN projects p ON p.id = a.project_id
+		WHERE a.recorded_at >= ?
+		  AND a.recorded_at <= ?
+		  AND a.state = ?
+		GROUP BY a.project_id
+		ORDER BY seconds DESC
+	`, utils.ToUTC(from), utils.ToUTC(to), StateActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []ProjectUsage
+	for rows.Next() {
+		var entry ProjectUsage
+		var projectID sql.NullInt64
+		var seconds int
+		if err := rows.Scan(&projectID, &entry.Name, &seconds); err != nil {
+			return nil, err
+		}
+		if projectID.Valid {
+			entry.ProjectID = &projectID.Int64
+		}
+		entry.Minutes = seconds / 60
+		usage = append(usage, entry)
+	}
+
+	return usage, rows.Err()
+}
+
+// GetTagReport returns the active time per tag in the time range
+func (r *ProjectRepo) GetTagReport(from, to time.Time) (map[string]int, error) {
+	rows, err := r.conn.Query(`
+		SELECT t.name, SUM(a.duration_seconds)
+		FROM activity_tags at
+		JOIN tags t ON t.id = at.tag_id
+		JOIN activity_logs a ON a.id = at.activity_id
+		WHERE a.recorded_at >= ?
+		  AND a.recorded_at <= ?
+		  AND a.state = ?
+		GROUP BY t.name
+	`, utils.ToUTC(from), utils.ToUTC(to), StateActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var seconds int
+		if err := rows.Scan(&name, &seconds); err != nil {
+			return nil, err
+		}
+		usage[name] = seconds / 60
+	}
+
+	return usage, rows.Err()
+}
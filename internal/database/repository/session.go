@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"shien/internal/utils"
+)
+
+// Session is a continuous period of work in one app
+type Session struct {
+	ID          int64         `json:"id"`
+	AppName     string        `json:"app_name"`
+	StartedAt   utils.UTCTime `json:"started_at"`
+	EndedAt     utils.UTCTime `json:"ended_at"`
+	SampleCount int           `json:"sample_count"`
+	DurationSec int           `json:"duration_seconds"` // Sum of the merged sample durations
+}
+
+// Duration returns the active time in the session
+func (s Session) Duration() time.Duration {
+	return time.Duration(s.DurationSec) * time.Second
+}
+
+// SessionRepo handles session persistence
+type SessionRepo struct {
+	conn *sql.DB
+}
+
+// NewSessionRepo creates a new session repository
+func NewSessionRepo(conn *sql.DB) *SessionRepo {
+	return &SessionRepo{conn: conn}
+}
+
+// GetLatestSession returns the most recent session, or nil if there is none
+func (r *SessionRepo) GetLatestSession() (*Session, error) {
+	var session Session
+	err := r.conn.QueryRow(`
+		SELECT id, app_name, started_at, ended_at, sample_count, duration_seconds
+		FROM sessions
+		ORDER BY ended_at DESC, id DESC
+		LIMIT 1
+	`).Scan(&session.ID, &session.AppName, &session.StartedAt, &session.EndedAt,
+		&session.SampleCount, &session.DurationSec)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CreateSession inserts a new session and sets its ID
+func (r *SessionRepo) CreateSession(session *Session) error {
+	result, err := r.conn.Exec(`
+		INSERT INTO sessions (app_name, started_at, ended_at, sample_count, duration_seconds)
+		VALUES (?, ?, ?, ?, ?)
+	`, session.AppName, session.StartedAt, session.EndedAt, session.SampleCount, session.DurationSec)
+	if err != nil {
+		return err
+	}
+
+	session.ID, err = result.LastInsertId()
+	return err
+}
+
+// ExtendSession moves the end of a session and adds a sample to it
+func (r *SessionRepo) ExtendSession(id int64, endedAt time.Time, duration time.Duration) error {
+	_, err := r.conn.Exec(`
+		UPDATE sessions SET
+			ended_at = ?,
+			sample_count = sample_count + 1,
+			duration_seconds = duration_seconds + ?
+		WHERE id = ?
+	`, utils.NewUTCTime(endedAt), int(duration.Seconds()), id)
+
+	return err
+}
+
+// GetSessions returns sessions overlapping the time range, oldest first
+func (r *SessionRepo) GetSessions(from, to time.Time) ([]Session, error) {
+	rows, err := r.conn.Query(`
+		SELECT id, app_name, started_at, ended_at, sample_count, duration_seconds
+		FROM sessions
+		WHERE ended_at >= ?
+		  AND started_at <= ?
+		ORDER BY started_at ASC
+	`, utils.ToUTC(from), utils.ToUTC(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(&session.ID, &session.AppName, &session.StartedAt, &session.EndedAt,
+			&session.SampleCount, &session.DurationSec)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// ReplaceSessions deletes the sessions overlapping a time range and inserts
+// the given ones in a single transaction
+func (r *SessionRepo) ReplaceSessions(from, to time.Time, sessions []Session) error {
+	tx, err := r.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM sessions
+		WHERE ended_at >= ?
+		  AND started_at <= ?
+	`, utils.ToUTC(from), utils.ToUTC(to)); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if _, err := tx.Exec(`
+			INSERT INTO sessions (app_name, started_at, ended_at, sample_count, duration_seconds)
+			VALUES (?, ?, ?, ?, ?)
+		`, session.AppName, session.StartedAt, session.EndedAt, session.SampleCount, session.DurationSec); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -4,7 +4,15 @@
 -- Activity logs table
 CREATE TABLE IF NOT EXISTS activity_logs (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
-    recorded_at DATETIME NOT NULL
+    recorded_at DATETIME NOT NULL,
+    app_name TEXT,      -- normalized category (e.g. "Code Editor")
+    raw_app_name TEXT,  -- app name as reported by the OS
+    window_title TEXT,
+    document TEXT,
+    url TEXT,
+    state TEXT NOT NULL DEFAULT 'active', -- 'active', 'idle', 'suspended' or 'paused'
+    duration_seconds INTEGER NOT NULL DEFAULT 300, -- time the record represents
+    project_id INTEGER REFERENCES projects(id)     -- NULL when unassigned
 );
 
 CREATE INDEX IF NOT EXISTS idx_activity_logs_recorded_at 
@@ -13,6 +21,76 @@ ON activity_logs(recorded_at);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_activity_logs_minute 
 ON activity_logs(strftime('%Y-%m-%d %H:%M', recorded_at));
 
+-- Sessions: consecutive samples of the same app merged into one period
+CREATE TABLE IF NOT EXISTS sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_name TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME NOT NULL,
+    sample_count INTEGER NOT NULL DEFAULT 0,
+    duration_seconds INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_started_at 
+ON sessions(started_at, ended_at);
+
+CREATE INDEX IF NOT EXISTS idx_activity_logs_project 
+ON activity_logs(project_id, recorded_at);
+
+-- Projects (or clients) that time is attributed to
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Rules assigning samples to projects automatically
+CREATE TABLE IF NOT EXISTS project_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,      -- 'app', 'title' or 'time'
+    pattern TEXT NOT NULL,   -- app name, title regex or HH:MM-HH:MM
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tags attached to activity records
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS activity_tags (
+    activity_id INTEGER NOT NULL REFERENCES activity_logs(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (activity_id, tag_id)
+);
+
+-- Manual entries: work done away from the computer, entered by the user
+CREATE TABLE IF NOT EXISTS manual_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME NOT NULL,
+    category TEXT NOT NULL,
+    note TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_manual_entries_started_at 
+ON manual_entries(started_at, ended_at);
+
+-- Pauses: periods in which tracking was paused (ended_at is NULL while paused)
+CREATE TABLE IF NOT EXISTS pauses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at DATETIME NOT NULL,
+    resume_at DATETIME,
+    ended_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_pauses_started_at 
+ON pauses(started_at, ended_at);
+
 -- Migrations tracking table
 CREATE TABLE IF NOT EXISTS migrations (
     version INTEGER PRIMARY KEY,
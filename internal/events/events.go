@@ -0,0 +1,354 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Topics published by the daemon. Subscribers can filter on a topic or on
+// a prefix of its dot-separated parts (e.g. "gamification").
+const (
+	TopicActivityRecorded = "activity.recorded"
+	TopicLevelUp          = "gamification.level_up"
+	TopicModifierApplied  = "gamification.modifier_applied"
+	TopicConfigChanged    = "config.changed"
+	TopicPauseChanged     = "tracking.pause_changed"
+)
+
+// Topics returns every topic published by the daemon
+func Topics() []string {
+	topics := make([]string, len(payloads))
+	for i, payload := range payloads {
+		topics[i] = payload.Topic()
+	}
+	return topics
+}
+
+// Payload is the data of an event. Each payload type has its own topic.
+type Payload interface {
+	Topic() string
+}
+
+// payloads holds the zero value of every payload type, in topic order
+var payloads = []Payload{
+	ActivityRecorded{},
+	LevelUp{},
+	ModifierApplied{},
+	ConfigChanged{},
+	PauseChanged{},
+}
+
+// Event is a message published on the bus
+type Event struct {
+	Topic string      `json:"topic"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// ActivityRecorded is published after a sample was stored
+type ActivityRecorded struct {
+	At          time.Time `json:"at"`               // Start of the period the sample covers
+	DurationSec int       `json:"duration_seconds"` // Time the sample represents
+	State       string    `json:"state"`            // active, idle, suspended or paused
+	AppName     string    `json:"app_name,omitempty"`
+	ProjectID   *int64    `json:"project_id,omitempty"`
+}
+
+// Topic returns the topic ActivityRecorded events are published on
+func (ActivityRecorded) Topic() string { return TopicActivityRecorded }
+
+// Duration returns the time the sample represents
+func (e ActivityRecorded) Duration() time.Duration {
+	return time.Duration(e.DurationSec) * time.Second
+}
+
+// LevelUp is published when a user reaches a new level
+type LevelUp struct {
+	UserID        string `json:"user_id"`
+	Level         int    `json:"level"`
+	PreviousLevel int    `json:"previous_level"`
+	TotalExp      int    `json:"total_exp"`
+}
+
+// Topic returns the topic LevelUp events are published on
+func (LevelUp) Topic() string { return TopicLevelUp }
+
+// ModifierApplied is published when an attribute modifier is applied
+type ModifierApplied struct {
+	UserID    string     `json:"user_id"`
+	Attribute string     `json:"attribute"`
+	Value     int        `json:"value"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Topic returns the topic ModifierApplied events are published on
+func (ModifierApplied) Topic() string { return TopicModifierApplied }
+
+// ConfigChanged is published when the effective configuration changed
+type ConfigChanged struct {
+	Changed []string `json:"changed"` // Keys whose value changed
+}
+
+// Topic returns the topic ConfigChanged events are published on
+func (ConfigChanged) Topic() string { return TopicConfigChanged }
+
+// PauseChanged is published when tracking is paused or resumed
+type PauseChanged struct {
+	Paused bool       `json:"paused"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"` // nil when paused until resumed
+}
+
+// Topic returns the topic PauseChanged events are published on
+func (PauseChanged) Topic() string { return TopicPauseChanged }
+
+// DefaultBuffer is the number of events a subscription holds before
+// further events are dropped for it
+const DefaultBuffer = 64
+
+// Bus delivers published events to subscribers. Publishing never blocks:
+// a subscriber that falls behind misses events instead of stalling the
+// daemon, except for handlers (see Handle), which queue every event.
+// A nil *Bus discards everything.
+type Bus struct {
+	subs   map[int]*Subscription
+	nextID int
+	mu     sync.RWMutex
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]*Subscription)}
+}
+
+// Publish sends an event to every subscriber whose filter matches the topic
+func (b *Bus) Publish(topic string, data interface{}) {
+	if b == nil {
+		return
+	}
+
+	event := Event{Topic: topic, Time: time.Now(), Data: data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if !sub.matches(topic) {
+			continue
+		}
+		if sub.wake != nil {
+			sub.enqueue(event)
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.drop(topic)
+		}
+	}
+}
+
+// Emit publishes a payload on its topic
+func (b *Bus) Emit(payload Payload) {
+	b.Publish(payload.Topic(), payload)
+}
+
+// Subscribe returns a subscription receiving events on the given topics,
+// or on every topic if none are given. buffer <= 0 uses DefaultBuffer.
+func (b *Bus) Subscribe(buffer int, topics ...string) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, topics: topics, bus: b}
+	if b == nil {
+		return sub
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub.id = b.nextID
+	b.nextID++
+	b.subs[sub.id] = sub
+	return sub
+}
+
+// Handle calls fn with every payload of type T published on the bus, in
+// order, from a goroutine of its own. Unlike other subscribers, a handler
+// never misses events: they queue up while fn is busy. Handling stops
+// after the queued events when the returned subscription is closed.
+func Handle[T Payload](b *Bus, fn func(T)) *Subscription {
+	var zero T
+	sub := b.subscribeQueued(zero.Topic())
+	go func() {
+		for {
+			queued, ok := sub.next()
+			if !ok {
+				return
+			}
+			for _, event := range queued {
+				if payload, ok := event.Data.(T); ok {
+					fn(payload)
+				}
+			}
+		}
+	}()
+	return sub
+}
+
+// subscribeQueued returns a subscription that queues events without limit
+// for next instead of delivering them on C
+func (b *Bus) subscribeQueued(topics ...string) *Subscription {
+	ch := make(chan Event)
+	sub := &Subscription{C: ch, ch: ch, topics: topics, bus: b, wake: make(chan struct{}, 1)}
+	if b == nil {
+		return sub
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub.id = b.nextID
+	b.nextID++
+	b.subs[sub.id] = sub
+	return sub
+}
+
+// Subscription receives events from a bus until closed
+type Subscription struct {
+	C       <-chan Event // Delivered events; closed by Close
+	ch      chan Event
+	topics  []string
+	bus     *Bus
+	id      int
+	dropped int
+	closed  bool
+	queue   []Event       // Events waiting for a handler
+	wake    chan struct{} // Signals queued events; nil unless queued
+	mu      sync.Mutex
+}
+
+// drop counts an event missed because the buffer was full. The first miss
+// and every hundredth after it are logged.
+func (s *Subscription) drop(topic string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped++
+	if s.dropped%100 == 1 {
+		log.Printf("Event subscriber is falling behind, dropped %s event (%d dropped so far)", topic, s.dropped)
+	}
+}
+
+// enqueue adds an event to the queue of a handler
+func (s *Subscription) enqueue(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.queue = append(s.queue, event)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for queued events and takes them. It returns false once the
+// subscription is closed and the queue is empty.
+func (s *Subscription) next() ([]Event, bool) {
+	for {
+		s.mu.Lock()
+		if queued := s.queue; len(queued) > 0 {
+			s.queue = nil
+			s.mu.Unlock()
+			return queued, true
+		}
+		closed := s.closed
+		s.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+		<-s.wake
+	}
+}
+
+// Close stops delivery and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	if s.bus != nil {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s.id)
+		s.bus.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+		if s.wake != nil {
+			close(s.wake)
+		}
+	}
+}
+
+// Dropped returns the number of events missed because the buffer was full
+func (s *Subscription) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// matches reports whether the subscription wants events on topic
+func (s *Subscription) matches(topic string) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	for _, filter := range s.topics {
+		if Match(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether a topic filter matches a topic. A filter matches
+// the topic itself and every topic below it ("gamification" matches
+// "gamification.level_up"); "*" matches everything.
+func Match(filter, topic string) bool {
+	return filter == "*" || filter == topic || strings.HasPrefix(topic, filter+".")
+}
+
+// DecodeData converts the data of an event decoded from JSON into the
+// payload type of its topic (e.g. LevelUp). Data of unknown topics is
+// returned unchanged.
+func DecodeData(event Event) (interface{}, error) {
+	if _, ok := event.Data.(Payload); ok {
+		return event.Data, nil
+	}
+
+	for _, payload := range payloads {
+		if payload.Topic() != event.Topic {
+			continue
+		}
+
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return nil, err
+		}
+		decoded := reflect.New(reflect.TypeOf(payload))
+		if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+			return nil, fmt.Errorf("invalid %s event: %w", event.Topic, err)
+		}
+
+		// Return the payload by value, as it is published
+		return decoded.Elem().Interface(), nil
+	}
+
+	return event.Data, nil
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandleReceivesEveryEvent(t *testing.T) {
+	bus := NewBus()
+
+	// The handler is stuck until every event was published, far more than
+	// a subscription buffer holds
+	const count = 10 * DefaultBuffer
+	release := make(chan struct{})
+	received := make(chan int, count)
+	sub := Handle(bus, func(e LevelUp) {
+		<-release
+		received <- e.Level
+	})
+	defer sub.Close()
+
+	for level := 0; level < count; level++ {
+		bus.Emit(LevelUp{Level: level})
+	}
+	close(release)
+
+	for want := 0; want < count; want++ {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("received level %d, want %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d events", want, count)
+		}
+	}
+	if dropped := sub.Dropped(); dropped != 0 {
+		t.Errorf("Dropped() = %d, want 0", dropped)
+	}
+}
+
+func TestHandleStopsWhenClosed(t *testing.T) {
+	bus := NewBus()
+	received := make(chan LevelUp, 1)
+	sub := Handle(bus, func(e LevelUp) { received <- e })
+
+	sub.Close()
+	bus.Emit(LevelUp{Level: 2})
+
+	select {
+	case e := <-received:
+		t.Errorf("handled %+v after Close", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(1, TopicLevelUp)
+	defer sub.Close()
+
+	for level := 1; level <= 3; level++ {
+		bus.Emit(LevelUp{Level: level})
+	}
+
+	if got := (<-sub.C).Data.(LevelUp).Level; got != 1 {
+		t.Errorf("delivered level %d, want 1", got)
+	}
+	if dropped := sub.Dropped(); dropped != 2 {
+		t.Errorf("Dropped() = %d, want 2", dropped)
+	}
+}
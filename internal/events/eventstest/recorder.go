@@ -0,0 +1,141 @@
+// Package eventstest provides helpers for asserting the events a component
+// publishes on an events.Bus.
+package eventstest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"shien/internal/events"
+)
+
+// DefaultTimeout is how long Expect waits for an event
+const DefaultTimeout = time.Second
+
+// Recorder keeps every event published on a bus from its creation on
+type Recorder struct {
+	sub    *events.Subscription
+	events []events.Event
+	added  chan struct{} // Closed and replaced whenever an event is recorded
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// NewRecorder records the events published on the given topics, or on
+// every topic if none are given. Close it when done.
+func NewRecorder(bus *events.Bus, topics ...string) *Recorder {
+	r := &Recorder{
+		sub:   bus.Subscribe(1024, topics...),
+		added: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go r.record()
+	return r
+}
+
+// record appends delivered events until the subscription is closed
+func (r *Recorder) record() {
+	defer close(r.done)
+	for event := range r.sub.C {
+		r.mu.Lock()
+		r.events = append(r.events, event)
+		close(r.added)
+		r.added = make(chan struct{})
+		r.mu.Unlock()
+	}
+}
+
+// Close stops recording
+func (r *Recorder) Close() {
+	r.sub.Close()
+	<-r.done
+}
+
+// Events returns the events recorded so far, oldest first
+func (r *Recorder) Events() []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.Event(nil), r.events...)
+}
+
+// Topics returns the topics of the events recorded so far, oldest first
+func (r *Recorder) Topics() []string {
+	recorded := r.Events()
+	topics := make([]string, len(recorded))
+	for i, event := range recorded {
+		topics[i] = event.Topic
+	}
+	return topics
+}
+
+// Reset forgets the events recorded so far
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// Wait returns the first recorded event on topic, waiting up to timeout
+// for it to be published
+func (r *Recorder) Wait(topic string, timeout time.Duration) (events.Event, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		r.mu.Lock()
+		for _, event := range r.events {
+			if event.Topic == topic {
+				r.mu.Unlock()
+				return event, nil
+			}
+		}
+		added := r.added
+		r.mu.Unlock()
+
+		select {
+		case <-added:
+		case <-deadline.C:
+			return events.Event{}, fmt.Errorf("no %s event within %s (recorded: %v)", topic, timeout, r.Topics())
+		}
+	}
+}
+
+// Payloads returns the payloads of type T recorded so far, oldest first
+func Payloads[T events.Payload](r *Recorder) []T {
+	var payloads []T
+	for _, event := range r.Events() {
+		if payload, ok := event.Data.(T); ok {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
+
+// Expect waits up to DefaultTimeout for a payload of type T and returns the
+// first one recorded, failing the test if none is published
+func Expect[T events.Payload](t testing.TB, r *Recorder) T {
+	t.Helper()
+
+	var zero T
+	event, err := r.Wait(zero.Topic(), DefaultTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, ok := event.Data.(T)
+	if !ok {
+		t.Fatalf("%s event has data of type %T, want %T", event.Topic, event.Data, zero)
+	}
+	return payload
+}
+
+// ExpectNone fails the test if an event on topic is recorded within the
+// given time
+func ExpectNone(t testing.TB, r *Recorder, topic string, within time.Duration) {
+	t.Helper()
+
+	if event, err := r.Wait(topic, within); err == nil {
+		t.Fatalf("unexpected %s event: %+v", event.Topic, event.Data)
+	}
+}
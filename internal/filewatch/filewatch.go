@@ -0,0 +1,53 @@
+package filewatch
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// DefaultInterval is how often watched files are checked for changes
+const DefaultInterval = 2 * time.Second
+
+// fileState identifies a version of a file
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// stat returns the current state of the file at path
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// Watch calls onChange whenever the file at path is created, modified or
+// removed, until ctx is canceled. Changes are detected by polling the
+// modification time and size, which works the same on every platform and
+// keeps working when editors replace the file instead of writing in place.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	last := stat(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := stat(path)
+			if current != last {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
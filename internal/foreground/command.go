@@ -0,0 +1,64 @@
+package foreground
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// defaultCommandTimeout bounds how long a user command may run
+const defaultCommandTimeout = 5 * time.Second
+
+// CommandProvider runs a user-configured shell command and reads the app
+// name from the first non-empty line of its stdout. An optional second
+// line is taken as the window title.
+type CommandProvider struct {
+	command string
+	timeout time.Duration
+}
+
+// NewCommandProvider creates a new command provider
+func NewCommandProvider(command string) *CommandProvider {
+	return &CommandProvider{
+		command: command,
+		timeout: defaultCommandTimeout,
+	}
+}
+
+// Name returns the provider name
+func (p *CommandProvider) Name() string {
+	return ProviderCommand
+}
+
+// Foreground runs the command and returns the reported window
+func (p *CommandProvider) Foreground() (*Window, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("foreground command failed: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	appName := strings.TrimSpace(lines[0])
+	if appName == "" {
+		return nil, fmt.Errorf("foreground command produced no output")
+	}
+
+	window := &Window{AppName: appName}
+	if len(lines) > 1 {
+		window.Title = strings.TrimSpace(lines[1])
+	}
+	return window, nil
+}
@@ -0,0 +1,59 @@
+package foreground
+
+import (
+	"fmt"
+
+	"shien/internal/config"
+	"shien/internal/utils"
+)
+
+// Provider names accepted in configuration
+const (
+	ProviderOS      = "os"
+	ProviderCommand = "command"
+)
+
+// Window describes the window in the foreground with its raw app name
+type Window = utils.ForegroundWindow
+
+// Provider reports the window currently in the foreground
+type Provider interface {
+	// Name identifies the provider in logs
+	Name() string
+	// Foreground returns the foreground window; normalization of the
+	// app name into a category is left to the caller
+	Foreground() (*Window, error)
+}
+
+// New creates the provider selected in configuration
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.ForegroundProvider {
+	case "", ProviderOS:
+		return NewOSProvider(), nil
+	case ProviderCommand:
+		if cfg.ForegroundCommand == "" {
+			return nil, fmt.Errorf("foreground_command must be set when foreground_provider is %q", ProviderCommand)
+		}
+		return NewCommandProvider(cfg.ForegroundCommand), nil
+	default:
+		return nil, fmt.Errorf("unknown foreground provider: %s", cfg.ForegroundProvider)
+	}
+}
+
+// OSProvider detects the foreground app with the native OS facilities
+type OSProvider struct{}
+
+// NewOSProvider creates a new OS provider
+func NewOSProvider() *OSProvider {
+	return &OSProvider{}
+}
+
+// Name returns the provider name
+func (p *OSProvider) Name() string {
+	return ProviderOS
+}
+
+// Foreground returns the foreground window reported by the OS
+func (p *OSProvider) Foreground() (*Window, error) {
+	return utils.GetForegroundWindow()
+}
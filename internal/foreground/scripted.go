@@ -0,0 +1,56 @@
+package foreground
+
+import "sync"
+
+// ScriptedResult is one answer returned by a ScriptedProvider
+type ScriptedResult struct {
+	App   string
+	Title string
+	Err   error
+}
+
+// ScriptedProvider returns a predefined sequence of results, for tests.
+// Once the script is exhausted the last result is repeated.
+type ScriptedProvider struct {
+	results []ScriptedResult
+	next    int
+	mu      sync.Mutex
+}
+
+// NewScriptedProvider creates a provider that replays the given results
+func NewScriptedProvider(results ...ScriptedResult) *ScriptedProvider {
+	return &ScriptedProvider{results: results}
+}
+
+// NewScriptedApps creates a provider that replays the given app names
+func NewScriptedApps(appNames ...string) *ScriptedProvider {
+	results := make([]ScriptedResult, len(appNames))
+	for i, appName := range appNames {
+		results[i] = ScriptedResult{App: appName}
+	}
+	return NewScriptedProvider(results...)
+}
+
+// Name returns the provider name
+func (p *ScriptedProvider) Name() string {
+	return "scripted"
+}
+
+// Foreground returns the next scripted result
+func (p *ScriptedProvider) Foreground() (*Window, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.results) == 0 {
+		return &Window{AppName: "Unknown"}, nil
+	}
+
+	result := p.results[p.next]
+	if p.next < len(p.results)-1 {
+		p.next++
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &Window{AppName: result.App, Title: result.Title}, nil
+}
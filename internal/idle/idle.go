@@ -0,0 +1,112 @@
+package idle
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source reports how long the user has been away from the keyboard and mouse
+type Source interface {
+	// Name identifies the source in logs
+	Name() string
+	// IdleTime returns the time since the last user input
+	IdleTime() (time.Duration, error)
+}
+
+// NewDefault returns the idle source for the current platform
+func NewDefault() Source {
+	switch runtime.GOOS {
+	case "darwin":
+		return NewIOKitSource()
+	case "linux":
+		var sources []Source
+		if os.Getenv("DISPLAY") != "" {
+			sources = append(sources, NewX11Source())
+		}
+		sources = append(sources, NewLogindSource(os.Getenv("XDG_SESSION_ID")))
+		return NewChain(sources...)
+	default:
+		return &NeverIdle{}
+	}
+}
+
+// Chain tries each source in order and returns the first successful answer
+type Chain struct {
+	sources []Source
+}
+
+// NewChain creates a chain of sources
+func NewChain(sources ...Source) *Chain {
+	return &Chain{sources: sources}
+}
+
+// Name returns the names of the chained sources
+func (c *Chain) Name() string {
+	names := make([]string, len(c.sources))
+	for i, source := range c.sources {
+		names[i] = source.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// IdleTime returns the idle time from the first source that succeeds
+func (c *Chain) IdleTime() (time.Duration, error) {
+	var failures []string
+	for _, source := range c.sources {
+		idle, err := source.IdleTime()
+		if err == nil {
+			return idle, nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", source.Name(), err))
+	}
+	return 0, fmt.Errorf("no idle source available (%s)", strings.Join(failures, "; "))
+}
+
+// NeverIdle is used on platforms without idle detection
+type NeverIdle struct{}
+
+// Name returns the source name
+func (n *NeverIdle) Name() string {
+	return "none"
+}
+
+// IdleTime always reports zero idle time
+func (n *NeverIdle) IdleTime() (time.Duration, error) {
+	return 0, nil
+}
+
+// FakeSource reports a configurable idle time, for tests
+type FakeSource struct {
+	idle time.Duration
+	err  error
+	mu   sync.Mutex
+}
+
+// NewFakeSource creates a fake source reporting the given idle time
+func NewFakeSource(idle time.Duration) *FakeSource {
+	return &FakeSource{idle: idle}
+}
+
+// Set changes the reported idle time and error
+func (f *FakeSource) Set(idle time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.idle = idle
+	f.err = err
+}
+
+// Name returns the source name
+func (f *FakeSource) Name() string {
+	return "fake"
+}
+
+// IdleTime returns the configured idle time
+func (f *FakeSource) IdleTime() (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.idle, f.err
+}
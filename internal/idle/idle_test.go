@@ -0,0 +1,136 @@
+package idle
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeRun returns a command runner answering every command with output
+func fakeRun(output string, err error) func(string, ...string) ([]byte, error) {
+	return func(string, ...string) ([]byte, error) {
+		return []byte(output), err
+	}
+}
+
+func TestX11Source(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "milliseconds", output: "1500\n", want: 1500 * time.Millisecond},
+		{name: "active", output: "0\n", want: 0},
+		{name: "garbage", output: "couldn't open display\n", wantErr: true},
+		{name: "not installed", err: errors.New("executable file not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &X11Source{run: fakeRun(tt.output, tt.err)}
+			assertIdle(t, source, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestLogindSource(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	since := now.Add(-10 * time.Minute).UnixMicro()
+
+	tests := []struct {
+		name    string
+		output  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "idle", output: "IdleHint=yes\nIdleSinceHint=" + itoa(since) + "\n", want: 10 * time.Minute},
+		{name: "active", output: "IdleHint=no\nIdleSinceHint=0\n", want: 0},
+		{name: "idle in the future", output: "IdleHint=yes\nIdleSinceHint=" + itoa(now.Add(time.Minute).UnixMicro()) + "\n", want: 0},
+		{name: "idle without a time", output: "IdleHint=yes\nIdleSinceHint=0\n", wantErr: true},
+		{name: "no properties", output: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &LogindSource{
+				sessionID: "auto",
+				run:       fakeRun(tt.output, nil),
+				now:       func() time.Time { return now },
+			}
+			assertIdle(t, source, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestIOKitSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "idle time",
+			output: "  | |   \"HIDIdleTime\" = 2500000000\n  | |   \"HIDPointerAcceleration\" = 45056\n",
+			want:   2500 * time.Millisecond,
+		},
+		{name: "missing", output: "  | |   \"HIDPointerAcceleration\" = 45056\n", wantErr: true},
+		{name: "not a number", output: "  | |   \"HIDIdleTime\" = <data>\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &IOKitSource{run: fakeRun(tt.output, nil)}
+			assertIdle(t, source, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	failing := NewFakeSource(0)
+	failing.Set(0, errors.New("no display"))
+
+	tests := []struct {
+		name    string
+		sources []Source
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "first answer wins", sources: []Source{NewFakeSource(time.Minute), NewFakeSource(time.Hour)}, want: time.Minute},
+		{name: "falls back after a failure", sources: []Source{failing, NewFakeSource(time.Hour)}, want: time.Hour},
+		{name: "every source fails", sources: []Source{failing}, wantErr: true},
+		{name: "no sources", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertIdle(t, NewChain(tt.sources...), tt.want, tt.wantErr)
+		})
+	}
+}
+
+// assertIdle checks the idle time a source reports
+func assertIdle(t *testing.T, source Source, want time.Duration, wantErr bool) {
+	t.Helper()
+
+	got, err := source.IdleTime()
+	if wantErr {
+		if err == nil {
+			t.Fatalf("IdleTime() = %s, want an error", got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("IdleTime() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("IdleTime() = %s, want %s", got, want)
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
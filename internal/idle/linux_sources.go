@@ -0,0 +1,102 @@
+package idle
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// X11Source reads the idle time from the X screensaver extension via xprintidle
+type X11Source struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+// NewX11Source creates a new X11 source
+func NewX11Source() *X11Source {
+	return &X11Source{run: runCommand}
+}
+
+// Name returns the source name
+func (s *X11Source) Name() string {
+	return "x11"
+}
+
+// IdleTime returns the idle time reported by the X server
+func (s *X11Source) IdleTime() (time.Duration, error) {
+	output, err := s.run("xprintidle")
+	if err != nil {
+		return 0, err
+	}
+
+	// xprintidle prints milliseconds
+	ms, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected xprintidle output: %q", output)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// LogindSource reads IdleHint from the systemd-logind session
+type LogindSource struct {
+	sessionID string
+	run       func(name string, args ...string) ([]byte, error)
+	now       func() time.Time
+}
+
+// NewLogindSource creates a logind source for the given session
+// (the caller's session when empty)
+func NewLogindSource(sessionID string) *LogindSource {
+	if sessionID == "" {
+		sessionID = "auto"
+	}
+	return &LogindSource{
+		sessionID: sessionID,
+		run:       runCommand,
+		now:       time.Now,
+	}
+}
+
+// Name returns the source name
+func (s *LogindSource) Name() string {
+	return "logind"
+}
+
+// IdleTime returns the time since logind marked the session idle
+func (s *LogindSource) IdleTime() (time.Duration, error) {
+	output, err := s.run("loginctl", "show-session", s.sessionID, "-p", "IdleHint", "-p", "IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+
+	// Output looks like:
+	//   IdleHint=yes
+	//   IdleSinceHint=1700000000000000
+	properties := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), "="); found {
+			properties[key] = value
+		}
+	}
+
+	if properties["IdleHint"] != "yes" {
+		return 0, nil
+	}
+
+	usec, err := strconv.ParseInt(properties["IdleSinceHint"], 10, 64)
+	if err != nil || usec == 0 {
+		return 0, fmt.Errorf("session is idle but IdleSinceHint is not set")
+	}
+
+	idle := s.now().Sub(time.UnixMicro(usec))
+	if idle < 0 {
+		idle = 0
+	}
+	return idle, nil
+}
+
+// runCommand runs an external command and returns its stdout
+func runCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
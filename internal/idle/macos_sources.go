@@ -0,0 +1,49 @@
+package idle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IOKitSource reads HIDIdleTime from the IOHIDSystem registry entry via ioreg
+type IOKitSource struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+// NewIOKitSource creates a new IOKit source
+func NewIOKitSource() *IOKitSource {
+	return &IOKitSource{run: runCommand}
+}
+
+// Name returns the source name
+func (s *IOKitSource) Name() string {
+	return "iokit"
+}
+
+// IdleTime returns the idle time reported by IOHIDSystem
+func (s *IOKitSource) IdleTime() (time.Duration, error) {
+	output, err := s.run("ioreg", "-c", "IOHIDSystem", "-d", "4")
+	if err != nil {
+		return 0, err
+	}
+
+	// The relevant line looks like: "HIDIdleTime" = 1234567890
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.Contains(line, `"HIDIdleTime"`) {
+			continue
+		}
+		_, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		ns, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected HIDIdleTime value: %q", value)
+		}
+		return time.Duration(ns), nil
+	}
+
+	return 0, fmt.Errorf("HIDIdleTime not found in ioreg output")
+}
@@ -55,6 +55,9 @@ type AttributeModifier struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
+// ImpactUnit is the amount of activity time each ActivityImpact describes
+const ImpactUnit = 5 * time.Minute
+
 // ActivityImpact defines how different activities affect status attributes
 type ActivityImpact struct {
 	AppName      string `json:"app_name"`
@@ -159,6 +162,40 @@ func PredefinedActivityImpacts() map[string]ActivityImpact {
 			CollaborationImpact: 10,
 			ExpGain:      8,
 		},
+		// Categories of manual entries for work away from the computer
+		"Meeting": {
+			AppName:      "Meeting",
+			Category:     "communication",
+			FocusImpact:  -3,
+			ProductivityImpact: 4,
+			CreativityImpact: 3,
+			StaminaCost:  3,
+			KnowledgeGain: 2,
+			CollaborationImpact: 10,
+			ExpGain:      8,
+		},
+		"Whiteboarding": {
+			AppName:      "Whiteboarding",
+			Category:     "creative",
+			FocusImpact:  6,
+			ProductivityImpact: 6,
+			CreativityImpact: 10,
+			StaminaCost:  3,
+			KnowledgeGain: 2,
+			CollaborationImpact: 4,
+			ExpGain:      12,
+		},
+		"Reading": {
+			AppName:      "Reading",
+			Category:     "learning",
+			FocusImpact:  7,
+			ProductivityImpact: 4,
+			CreativityImpact: 3,
+			StaminaCost:  1,
+			KnowledgeGain: 10,
+			CollaborationImpact: 0,
+			ExpGain:      10,
+		},
 	}
 }
 
@@ -97,11 +97,31 @@ func DatabaseFile() string {
 	return filepath.Join(dataDir, "shien.db")
 }
 
+func AppRulesFile() string {
+	initDataDir()
+	return filepath.Join(dataDir, "app_rules.json")
+}
+
 func SocketFile() string {
 	initDataDir()
 	return filepath.Join(dataDir, "shien-service.sock")
 }
 
+func HTTPTokenFile() string {
+	initDataDir()
+	return filepath.Join(dataDir, "http_token")
+}
+
+func TokensFile() string {
+	initDataDir()
+	return filepath.Join(dataDir, "tokens.json")
+}
+
+func AuditLogFile() string {
+	initDataDir()
+	return filepath.Join(dataDir, "audit.log")
+}
+
 func IsDevMode() bool {
 	initDataDir()
 	// Dev mode if not using default path
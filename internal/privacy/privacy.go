@@ -0,0 +1,166 @@
+package privacy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"shien/internal/utils"
+)
+
+// Actions applied to matching samples
+const (
+	ActionDrop    = "drop"    // Don't record the sample at all
+	ActionPrivate = "private" // Record the time as "Private" without any details
+	ActionRedact  = "redact"  // Keep the app but remove the title, document and URL
+	ActionRecord  = "record"  // No rule matched; the sample is recorded as is
+)
+
+// PrivateAppName is recorded in place of the app for private samples
+const PrivateAppName = "Private"
+
+// Rule matches windows by app name and/or title. All fields that are set
+// must match.
+type Rule struct {
+	App    string `json:"app,omitempty"`   // Glob on the raw app name (case-insensitive), e.g. "1Password*"
+	Title  string `json:"title,omitempty"` // Regular expression on the window title
+	Action string `json:"action"`          // "drop", "private" or "redact"
+}
+
+// String describes the rule for display
+func (r Rule) String() string {
+	var parts []string
+	if r.App != "" {
+		parts = append(parts, fmt.Sprintf("app %q", r.App))
+	}
+	if r.Title != "" {
+		parts = append(parts, fmt.Sprintf("title /%s/", r.Title))
+	}
+	return strings.Join(parts, " and ") + " -> " + r.Action
+}
+
+// DefaultRules returns the rules used when none are configured
+func DefaultRules() []Rule {
+	return []Rule{
+		{App: "1Password*", Action: ActionPrivate},
+		{App: "Bitwarden", Action: ActionPrivate},
+		{App: "KeePass*", Action: ActionPrivate},
+		{App: "Keychain Access", Action: ActionPrivate},
+		{Title: `(?i)private browsing|incognito|inprivate`, Action: ActionRedact},
+	}
+}
+
+// Decision is how a sample is handled
+type Decision struct {
+	Action string                  `json:"action"`
+	Rule   *Rule                   `json:"rule,omitempty"`   // The matching rule, nil when none matched
+	Window *utils.ForegroundWindow `json:"window,omitempty"` // What is recorded; nil when dropped
+}
+
+// compiledRule is a rule ready for matching
+type compiledRule struct {
+	Rule
+	title *regexp.Regexp
+}
+
+// Policy applies privacy rules to samples. The first matching rule wins.
+type Policy struct {
+	rules      []compiledRule
+	failClosed bool
+}
+
+// NewPolicy compiles the rules
+func NewPolicy(rules []Rule) (*Policy, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("privacy rule %d: %w", i+1, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return &Policy{rules: compiled}, nil
+}
+
+// RedactAll returns a policy that redacts every sample. It is used when the
+// configured rules are invalid so nothing that should be hidden is recorded.
+func RedactAll() *Policy {
+	return &Policy{failClosed: true}
+}
+
+// compile validates a rule and compiles its patterns
+func compile(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+
+	switch rule.Action {
+	case ActionDrop, ActionPrivate, ActionRedact:
+	default:
+		return c, fmt.Errorf("unknown action %q (want %s, %s or %s)", rule.Action, ActionDrop, ActionPrivate, ActionRedact)
+	}
+
+	if rule.App == "" && rule.Title == "" {
+		return c, fmt.Errorf("app or title is required")
+	}
+
+	if rule.App != "" {
+		if _, err := path.Match(rule.App, ""); err != nil {
+			return c, fmt.Errorf("invalid app pattern %q: %w", rule.App, err)
+		}
+	}
+
+	if rule.Title != "" {
+		re, err := regexp.Compile(rule.Title)
+		if err != nil {
+			return c, fmt.Errorf("invalid title pattern %q: %w", rule.Title, err)
+		}
+		c.title = re
+	}
+
+	return c, nil
+}
+
+// matches reports whether the rule applies to a window
+func (r compiledRule) matches(window *utils.ForegroundWindow) bool {
+	if r.App != "" {
+		matched, _ := path.Match(strings.ToLower(r.App), strings.ToLower(window.AppName))
+		if !matched {
+			return false
+		}
+	}
+	if r.title != nil && !r.title.MatchString(window.Title) {
+		return false
+	}
+	return true
+}
+
+// Apply decides how a window is recorded. The given window is not modified.
+func (p *Policy) Apply(window *utils.ForegroundWindow) Decision {
+	if p.failClosed {
+		return Decision{Action: ActionRedact, Window: redact(window)}
+	}
+
+	for i := range p.rules {
+		if !p.rules[i].matches(window) {
+			continue
+		}
+
+		rule := p.rules[i].Rule
+		decision := Decision{Action: rule.Action, Rule: &rule}
+		switch rule.Action {
+		case ActionPrivate:
+			decision.Window = &utils.ForegroundWindow{AppName: PrivateAppName}
+		case ActionRedact:
+			decision.Window = redact(window)
+		}
+		return decision
+	}
+
+	recorded := *window
+	return Decision{Action: ActionRecord, Window: &recorded}
+}
+
+// redact returns a copy of the window with only the app name
+func redact(window *utils.ForegroundWindow) *utils.ForegroundWindow {
+	return &utils.ForegroundWindow{AppName: window.AppName}
+}
@@ -0,0 +1,152 @@
+package projects
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rule kinds
+const (
+	KindApp   = "app"   // Matches the app category or raw app name (case-insensitive)
+	KindTitle = "title" // Matches the window title with a regular expression
+	KindTime  = "time"  // Matches the local time of day, e.g. "09:00-12:30"
+)
+
+// Rule assigns matching samples to a project
+type Rule struct {
+	ID        int64
+	ProjectID int64
+	Kind      string
+	Pattern   string
+	Priority  int // Higher priorities are checked first
+}
+
+// Input is the sample information rules are matched against
+type Input struct {
+	At         time.Time
+	AppName    string // Normalized category
+	RawAppName string
+	Title      string
+}
+
+// compiledRule is a rule ready for matching
+type compiledRule struct {
+	Rule
+	title    *regexp.Regexp
+	from, to int // Minutes since midnight
+}
+
+// Engine matches samples against an ordered set of rules
+type Engine struct {
+	rules []compiledRule
+}
+
+// NewEngine compiles the rules. Rules are checked by descending priority,
+// then in the order given.
+func NewEngine(rules []Rule) (*Engine, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", rule.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority > compiled[j].Priority
+	})
+
+	return &Engine{rules: compiled}, nil
+}
+
+// Validate reports whether a rule can be compiled
+func Validate(rule Rule) error {
+	_, err := compile(rule)
+	return err
+}
+
+// Match returns the project of the first matching rule
+func (e *Engine) Match(in Input) (int64, bool) {
+	for _, rule := range e.rules {
+		if rule.matches(in) {
+			return rule.ProjectID, true
+		}
+	}
+	return 0, false
+}
+
+// compile parses the pattern of a rule
+func compile(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+
+	switch rule.Kind {
+	case KindApp:
+		if strings.TrimSpace(rule.Pattern) == "" {
+			return c, fmt.Errorf("app pattern is empty")
+		}
+
+	case KindTitle:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return c, fmt.Errorf("invalid title pattern: %w", err)
+		}
+		c.title = re
+
+	case KindTime:
+		from, to, err := parseTimeRange(rule.Pattern)
+		if err != nil {
+			return c, err
+		}
+		c.from, c.to = from, to
+
+	default:
+		return c, fmt.Errorf("unknown rule kind %q (want %s, %s or %s)", rule.Kind, KindApp, KindTitle, KindTime)
+	}
+
+	return c, nil
+}
+
+// matches reports whether the rule applies to the sample
+func (r compiledRule) matches(in Input) bool {
+	switch r.Kind {
+	case KindApp:
+		return strings.EqualFold(r.Pattern, in.AppName) || strings.EqualFold(r.Pattern, in.RawAppName)
+	case KindTitle:
+		return in.Title != "" && r.title.MatchString(in.Title)
+	case KindTime:
+		minute := in.At.Hour()*60 + in.At.Minute()
+		if r.from <= r.to {
+			return minute >= r.from && minute < r.to
+		}
+		// Ranges past midnight, e.g. 22:00-02:00
+		return minute >= r.from || minute < r.to
+	}
+	return false
+}
+
+// parseTimeRange parses "HH:MM-HH:MM" into minutes since midnight
+func parseTimeRange(pattern string) (int, int, error) {
+	parts := strings.Split(pattern, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time range %q (want HH:MM-HH:MM)", pattern)
+	}
+
+	var minutes [2]int
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time range %q (want HH:MM-HH:MM)", pattern)
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+
+	if minutes[0] == minutes[1] {
+		return 0, 0, fmt.Errorf("empty time range %q", pattern)
+	}
+
+	return minutes[0], minutes[1], nil
+}
@@ -2,17 +2,30 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 	
+	"shien/internal/events"
 	"shien/internal/paths"
 )
 
-// Client connects to the RPC server
+// Client connects to the RPC server. Calls share one connection, opened by
+// the first call and released by Close.
 type Client struct {
 	socketPath string
+	conn       net.Conn
+	decoder    *json.Decoder
+	encoder    *json.Encoder
+	nextID     int64
+	legacy     bool   // The daemon only speaks the envelope predating JSON-RPC
+	token      string // Secret of the client token each connection authenticates with
+	mu         sync.Mutex
 }
 
 // NewClient creates a new RPC client
@@ -24,40 +37,352 @@ func NewClient() (*Client, error) {
 	}, nil
 }
 
-// Call makes an RPC call to the server
+// SetToken sets the client token connections authenticate with, so calls
+// get its scope instead of the daemon's rpc_default_scope. It applies from
+// the next connection.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Close closes the connection to the daemon, if one is open
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.disconnect()
+}
+
+// Call makes an RPC call to the server. Errors reported by the daemon are
+// returned in the response; the error is for failing to reach it.
 func (c *Client) Call(method string, params map[string]interface{}) (*Response, error) {
+	var data interface{}
+	err := c.Invoke(method, params, &data)
+	
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return &Response{
+			Success:   false,
+			Error:     rpcErr.Message,
+			Code:      rpcErr.Code,
+			ErrorData: rpcErr.Data,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	return &Response{
+		Success: true,
+		Data:    data,
+	}, nil
+}
+
+// Invoke calls a method with params, which must encode to a JSON object or
+// be nil, and decodes the result into result unless it is nil. Errors
+// reported by the daemon are returned as *Error.
+func (c *Client) Invoke(method string, params interface{}, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	req, err := c.request(method, params)
+	if err != nil {
+		return err
+	}
+	
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	
+	resp, err := decodeReply(reply)
+	if err != nil {
+		return err
+	}
+	if !c.legacy && string(resp.ID) != string(req.ID) {
+		return fmt.Errorf("response id %s does not match request id %s", resp.ID, req.ID)
+	}
+	return resp.decode(result)
+}
+
+// BatchCall is a call sent in a batch. Batch sets Err, and decodes the
+// result into Result unless it is nil.
+type BatchCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Err    error // *Error if the daemon reported an error
+}
+
+// Batch sends calls in one request. The error is for failing to reach the
+// daemon; the outcome of each call is in its Err.
+func (c *Client) Batch(calls []BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	
+	c.mu.Lock()
+	if c.legacy {
+		c.mu.Unlock()
+		return c.batchOneByOne(calls)
+	}
+	
+	requests := make([]*jsonrpcRequest, len(calls))
+	index := make(map[string]int, len(calls)) // Request id -> call
+	for i, call := range calls {
+		req, err := c.request(call.Method, call.Params)
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		requests[i] = req
+		index[string(req.ID)] = i
+	}
+	
+	reply, err := c.roundTrip(requests)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	
+	switch classify(reply) {
+	case kindLegacy:
+		// Daemons predating JSON-RPC reject the batch as a whole
+		return c.batchOneByOne(calls)
+	case kindSingle:
+		// The batch itself was rejected
+		resp, err := decodeReply(reply)
+		if err != nil {
+			return err
+		}
+		return resp.decode(nil)
+	}
+	
+	var responses []jsonrpcResponse
+	if err := json.Unmarshal(reply, &responses); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	for _, resp := range responses {
+		i, ok := index[string(resp.ID)]
+		if !ok {
+			continue
+		}
+		calls[i].Err = resp.decode(calls[i].Result)
+		delete(index, string(resp.ID))
+	}
+	for _, i := range index {
+		calls[i].Err = fmt.Errorf("no response to %s", calls[i].Method)
+	}
+	
+	return nil
+}
+
+// batchOneByOne makes the calls of a batch one at a time
+func (c *Client) batchOneByOne(calls []BatchCall) error {
+	for i := range calls {
+		err := c.Invoke(calls[i].Method, calls[i].Params, calls[i].Result)
+		var rpcErr *Error
+		if err != nil && !errors.As(err, &rpcErr) {
+			return err
+		}
+		calls[i].Err = err
+	}
+	return nil
+}
+
+// request builds a request with the next id. Must be called with the lock held.
+func (c *Client) request(method string, params interface{}) (*jsonrpcRequest, error) {
+	c.nextID++
+	req := &jsonrpcRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage(strconv.FormatInt(c.nextID, 10)),
+		Method:  method,
+	}
+	
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode params: %w", err)
+		}
+		if string(data) != "null" {
+			req.Params = data
+		}
+	}
+	return req, nil
+}
+
+// roundTrip sends a message and reads the reply. A connection the daemon
+// closed since the last call, e.g. because it restarted, is reopened once.
+// Must be called with the lock held.
+func (c *Client) roundTrip(message interface{}) (json.RawMessage, error) {
+	reused := c.conn != nil
+	reply, err := c.exchange(message)
+	
+	var netErr net.Error
+	if err != nil && reused && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		reply, err = c.exchange(message)
+	}
+	return reply, err
+}
+
+// exchange sends a message on the connection, opening it if needed, and
+// reads the reply. Must be called with the lock held.
+func (c *Client) exchange(message interface{}) (json.RawMessage, error) {
+	if c.conn == nil {
+		conn, err := c.dial()
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+		c.decoder = json.NewDecoder(conn)
+		c.encoder = json.NewEncoder(conn)
+		
+		if c.token != "" && !c.legacy {
+			legacy, err := authenticate(conn, c.encoder, c.decoder, c.token)
+			if err != nil {
+				c.disconnect()
+				return nil, err
+			}
+			if legacy {
+				// The daemon closed the connection and has no tokens to check
+				c.legacy = true
+				c.disconnect()
+				return c.exchange(message)
+			}
+		}
+	}
+	
+	// Set timeout for the entire operation
+	c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	
+	if err := c.encoder.Encode(message); err != nil {
+		c.disconnect()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	
+	var reply json.RawMessage
+	if err := c.decoder.Decode(&reply); err != nil {
+		c.disconnect()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	
+	// Daemons predating JSON-RPC close the connection after each response
+	if classify(reply) == kindLegacy {
+		c.legacy = true
+		c.disconnect()
+	}
+	
+	return reply, nil
+}
+
+// dial connects to the daemon's socket
+func (c *Client) dial() (net.Conn, error) {
 	// Check if socket exists
 	if _, err := os.Stat(c.socketPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("daemon not running (socket not found)")
 	}
 	
-	// Connect to socket
 	conn, err := net.DialTimeout("unix", c.socketPath, 5*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
 	}
-	defer conn.Close()
+	return conn, nil
+}
+
+// authenticate sends the token on a new connection. It reports whether the
+// daemon predates JSON-RPC, in which case it closed the connection. Daemons
+// predating tokens serve every client, so their refusal is ignored.
+func authenticate(conn net.Conn, encoder *json.Encoder, decoder *json.Decoder, token string) (legacy bool, err error) {
+	params, err := json.Marshal(AuthenticateParams{Token: token})
+	if err != nil {
+		return false, err
+	}
 	
-	// Set timeout for the entire operation
 	conn.SetDeadline(time.Now().Add(10 * time.Second))
-	
-	// Send request
-	encoder := json.NewEncoder(conn)
-	if err := encoder.Encode(Request{
-		Method: method,
-		Params: params,
+	if err := encoder.Encode(jsonrpcRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage("0"),
+		Method:  MethodAuthenticate,
+		Params:  params,
 	}); err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return false, fmt.Errorf("failed to send request: %w", err)
 	}
 	
-	// Read response
-	decoder := json.NewDecoder(conn)
-	var response Response
-	if err := decoder.Decode(&response); err != nil {
+	var reply json.RawMessage
+	if err := decoder.Decode(&reply); err != nil {
+		return false, fmt.Errorf("failed to read response: %w", err)
+	}
+	if classify(reply) == kindLegacy {
+		return true, nil
+	}
+	
+	resp, err := decodeReply(reply)
+	if err != nil {
+		return false, err
+	}
+	var rpcErr *Error
+	if err := resp.decode(nil); err != nil {
+		if errors.As(err, &rpcErr) && rpcErr.Code == CodeMethodNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("authentication failed: %w", err)
+	}
+	return false, nil
+}
+
+// disconnect closes the connection. Must be called with the lock held.
+func (c *Client) disconnect() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.decoder = nil
+	c.encoder = nil
+	return err
+}
+
+// decodeReply reads a response, converting one in the earlier envelope
+func decodeReply(reply json.RawMessage) (*jsonrpcResponse, error) {
+	if classify(reply) != kindLegacy {
+		var resp jsonrpcResponse
+		if err := json.Unmarshal(reply, &resp); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return &resp, nil
+	}
+	
+	var legacy Response
+	if err := json.Unmarshal(reply, &legacy); err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	if !legacy.Success {
+		if legacy.Code == 0 {
+			legacy.Code = legacyCode(legacy.Error)
+		}
+		return errorResponseFor(nil, legacy.Err()), nil
+	}
 	
-	return &response, nil
+	result, err := json.Marshal(legacy.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonrpcResponse{JSONRPC: JSONRPCVersion, ID: nullID, Result: result}, nil
+}
+
+// decode returns the error of a response, or decodes its result into
+// result unless it is nil
+func (r *jsonrpcResponse) decode(result interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if result == nil || len(r.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
 }
 
 // Ping checks if the daemon is running
@@ -157,4 +482,95 @@ func (c *Client) GetGamificationDetails(userID string) (*GamificationDetails, er
 	}
 	
 	return &details, nil
-}
\ No newline at end of file
+}
+
+// Subscribe streams events on the given topics (all topics if none) to
+// handle until the daemon stops, the connection fails or handle returns an
+// error, which Subscribe then returns. It uses a connection of its own.
+func (c *Client) Subscribe(topics []string, handle func(events.Event) error) error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer func() { conn.Close() }()
+	
+	encoder := json.NewEncoder(conn)
+	decoder := json.NewDecoder(conn)
+	
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+	if token != "" {
+		legacy, err := authenticate(conn, encoder, decoder, token)
+		if err != nil {
+			return err
+		}
+		if legacy {
+			conn.Close()
+			if conn, err = c.dial(); err != nil {
+				return err
+			}
+			encoder = json.NewEncoder(conn)
+			decoder = json.NewDecoder(conn)
+		}
+	}
+	
+	// Only the subscription itself has a deadline; events may be far apart
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	
+	params, err := json.Marshal(map[string]interface{}{"topics": topics})
+	if err != nil {
+		return err
+	}
+	
+	if err := encoder.Encode(jsonrpcRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      json.RawMessage("1"),
+		Method:  MethodSubscribe,
+		Params:  params,
+	}); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	
+	var reply json.RawMessage
+	if err := decoder.Decode(&reply); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	resp, err := decodeReply(reply)
+	if err != nil {
+		return err
+	}
+	if err := resp.decode(nil); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	
+	// Daemons predating JSON-RPC send bare events
+	legacy := classify(reply) == kindLegacy
+	for {
+		var message struct {
+			Method string       `json:"method"`
+			Params events.Event `json:"params"`
+		}
+		var err error
+		if legacy {
+			message.Method = NotificationEvent
+			err = decoder.Decode(&message.Params)
+		} else {
+			err = decoder.Decode(&message)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("daemon closed the connection")
+			}
+			return fmt.Errorf("failed to read event: %w", err)
+		}
+		
+		if message.Method != NotificationEvent {
+			continue
+		}
+		if err := handle(message.Params); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"shien/internal/auth"
+	"shien/internal/dashboard"
+	"shien/internal/events"
+	"shien/internal/paths"
+)
+
+// maxBodySize limits the params of HTTP requests
+const maxBodySize = 1 << 20
+
+// Gateway serves the RPC methods as REST/JSON endpoints on localhost, for
+// browsers and scripts that don't speak the socket protocol. Requests are
+// handled by the methods of the Server and must carry the token stored in
+// the data directory, which allows every method, or a client token, which
+// allows the methods of its scope:
+//
+//	GET  /api                 Describe the methods (as rpc.discover)
+//	GET  /api/<method>?k=v    Call a method with params from the query
+//	POST /api/<method>        Call a method with a JSON object of params
+//	GET  /api/subscribe       Stream events as server-sent events
+//	GET  /                    The dashboard
+type Gateway struct {
+	server     *Server
+	tokenPath  string
+	token      string
+	httpServer *http.Server
+	addr       string
+	mu         sync.Mutex
+}
+
+// NewGateway creates a gateway for the methods of a server
+func NewGateway(server *Server) *Gateway {
+	return &Gateway{
+		server:    server,
+		tokenPath: paths.HTTPTokenFile(),
+	}
+}
+
+// Start listens on the port on localhost. The token is created on first use.
+func (g *Gateway) Start(port int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.httpServer != nil {
+		return fmt.Errorf("gateway is already listening on %s", g.addr)
+	}
+
+	token, err := loadToken(g.tokenPath)
+	if err != nil {
+		return fmt.Errorf("failed to load gateway token: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+
+	g.token = token
+	g.addr = listener.Addr().String()
+	g.httpServer = &http.Server{
+		Handler:           g.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func(httpServer *http.Server) {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP gateway stopped: %v", err)
+		}
+	}(g.httpServer)
+	return nil
+}
+
+// Stop stops the gateway and ends its event streams. It does nothing if
+// the gateway is not running.
+func (g *Gateway) Stop() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.httpServer == nil {
+		return nil
+	}
+	err := g.httpServer.Close()
+	g.httpServer = nil
+	g.addr = ""
+	return err
+}
+
+// Addr returns the address the gateway listens on, empty when stopped
+func (g *Gateway) Addr() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.addr
+}
+
+// routes maps the endpoints to their handlers. The API is behind the token
+// check; the dashboard asks for the token itself.
+func (g *Gateway) routes() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /api", g.discover)
+	api.HandleFunc("GET /api/subscribe", g.subscribe)
+	api.HandleFunc("POST /api/subscribe", g.subscribe)
+	api.HandleFunc("GET /api/{method}", g.call)
+	api.HandleFunc("POST /api/{method}", g.call)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api", g.authorize(api))
+	mux.Handle("/api/", g.authorize(api))
+	mux.Handle("/", dashboard.Handler())
+	return mux
+}
+
+// callerKey is the context key of the caller of a request
+type callerKey struct{}
+
+// authorize rejects requests without the gateway token or a client token as
+// a bearer token, and passes on the caller in the request's context
+func (g *Gateway) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := g.identify(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="shien"`)
+			writeHTTPError(w, newError(CodeUnauthorized, "", "", "missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, c)))
+	})
+}
+
+// identify returns the caller of a request by its bearer token
+func (g *Gateway) identify(r *http.Request) (*caller, bool) {
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(g.token)) == 1 {
+		return &caller{transport: transportHTTP, uid: -1, scope: auth.ScopeAdmin}, true
+	}
+	if token, ok := g.server.tokens.Lookup(secret); ok {
+		return &caller{transport: transportHTTP, uid: -1, token: token.Name, secret: secret}, true
+	}
+	return nil, false
+}
+
+// callerOf returns the caller authorize found for a request
+func callerOf(r *http.Request) *caller {
+	return r.Context().Value(callerKey{}).(*caller)
+}
+
+// discover describes the methods
+func (g *Gateway) discover(w http.ResponseWriter, r *http.Request) {
+	g.respond(w, r, Request{Method: MethodDiscover, Params: map[string]interface{}{}})
+}
+
+// call calls the method named by the path. Only read-only methods may be
+// called with GET, so that following a link never changes anything.
+func (g *Gateway) call(w http.ResponseWriter, r *http.Request) {
+	method := r.PathValue("method")
+	if r.Method == http.MethodGet {
+		if info, ok := g.server.methods.Lookup(method); ok && !info.ReadOnly {
+			w.Header().Set("Allow", http.MethodPost)
+			writeHTTPErrorStatus(w, http.StatusMethodNotAllowed,
+				newError(CodeInvalidRequest, method, "", method+" changes state and must be called with POST"))
+			return
+		}
+	}
+
+	params, err := g.params(r, method)
+	if err != nil {
+		writeHTTPError(w, newError(CodeInvalidParams, method, "", err.Error()))
+		return
+	}
+	g.respond(w, r, Request{Method: method, Params: params})
+}
+
+// respond handles a request and writes its result, or its error with a
+// matching status
+func (g *Gateway) respond(w http.ResponseWriter, r *http.Request, req Request) {
+	resp := g.server.call(callerOf(r), req)
+	if !resp.Success {
+		err := resp.Err()
+		err.Data.Method = req.Method
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp.Data); err != nil {
+		return
+	}
+
+	// The response must be out before shutting down closes the gateway
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	g.server.afterResponse(req, resp)
+}
+
+// subscribe streams events as server-sent events. The first event,
+// "subscribed", acknowledges the topics; each event after it is a message
+// whose data is an events.Event.
+func (g *Gateway) subscribe(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeHTTPError(w, newError(CodeInternalError, MethodSubscribe, "", "streaming is not supported"))
+		return
+	}
+
+	params, err := g.params(r, MethodSubscribe)
+	if err != nil {
+		writeHTTPError(w, newError(CodeInvalidParams, MethodSubscribe, "", err.Error()))
+		return
+	}
+
+	req := Request{Method: MethodSubscribe, Params: params}
+	g.server.stream(callerOf(r), req, r.Context().Done(), func(resp Response) error {
+		if !resp.Success {
+			err := resp.Err()
+			err.Data.Method = MethodSubscribe
+			writeHTTPError(w, err)
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		return writeServerEvent(w, flusher, "subscribed", resp.Data)
+	}, func(event events.Event) error {
+		return writeServerEvent(w, flusher, "", event)
+	})
+}
+
+// writeServerEvent writes a server-sent event with JSON data. An empty name
+// sends a plain message.
+func writeServerEvent(w io.Writer, flusher http.Flusher, name string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if name != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", name); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", encoded); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// params reads the params of a request: a JSON object in the body of POST
+// requests, or the query, converted to the types the method declares
+func (g *Gateway) params(r *http.Request, method string) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	if r.Method != http.MethodPost {
+		return g.queryParams(method, r.URL.Query()), nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read params: %w", err)
+	}
+	if strings.TrimSpace(string(body)) == "" {
+		return params, nil
+	}
+	if err := json.Unmarshal(body, &params); err != nil || params == nil {
+		return nil, fmt.Errorf("params must be a JSON object")
+	}
+	return params, nil
+}
+
+// queryParams converts query parameters to the JSON types of the method's
+// params. Values that don't convert are passed as strings so the method
+// reports them.
+func (g *Gateway) queryParams(method string, query url.Values) map[string]interface{} {
+	types := map[string]string{}
+	if info, ok := g.server.methods.Lookup(method); ok {
+		for _, param := range info.Params {
+			types[param.Name] = param.Type
+		}
+	}
+
+	params := make(map[string]interface{}, len(query))
+	for key, values := range query {
+		params[key] = queryValue(types[key], values)
+	}
+	return params
+}
+
+// queryValue converts the values of a query parameter to a param type.
+// Arrays are given as repeated or comma-separated values.
+func queryValue(paramType string, values []string) interface{} {
+	if itemType, ok := strings.CutPrefix(paramType, "array of "); ok {
+		items := []interface{}{}
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, queryValue(itemType, []string{item}))
+				}
+			}
+		}
+		return items
+	}
+
+	value := values[len(values)-1]
+	switch paramType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// writeHTTPError writes an error as {"error": {...}} with the HTTP status
+// matching its code
+func writeHTTPError(w http.ResponseWriter, err *Error) {
+	writeHTTPErrorStatus(w, httpStatus(err.Code), err)
+}
+
+// writeHTTPErrorStatus writes an error with the given HTTP status
+func writeHTTPErrorStatus(w http.ResponseWriter, status int, err *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]*Error{"error": err})
+}
+
+// httpStatus maps an error code to an HTTP status
+func httpStatus(code int) int {
+	switch code {
+	case CodeParseError, CodeInvalidRequest, CodeInvalidParams:
+		return http.StatusBadRequest
+	case CodeMethodNotFound:
+		return http.StatusNotFound
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeUnsupported:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// loadToken reads the gateway token, creating a random one if the file is
+// missing or empty. Only the user can read the file.
+func loadToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if token := strings.TrimSpace(string(data)); token != "" {
+		return token, nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(secret)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
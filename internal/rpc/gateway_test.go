@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shien/internal/database"
+	"shien/internal/foreground"
+	"shien/internal/idle"
+	"shien/internal/paths"
+	"shien/internal/service"
+)
+
+// newTestServer returns a server over a fresh database
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	if err := paths.SetDataDir(t.TempDir()); err != nil {
+		t.Fatalf("SetDataDir() error = %v", err)
+	}
+	db, err := database.New()
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	services := service.NewServices(database.NewRepository(db), nil, foreground.NewScriptedApps("Terminal"), &idle.NeverIdle{})
+	server, err := NewServer(services)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return server
+}
+
+// newTestGateway returns the routes of a gateway accepting the token
+// "secret"
+func newTestGateway(t *testing.T) http.Handler {
+	t.Helper()
+
+	gateway := NewGateway(newTestServer(t))
+	gateway.token = "secret"
+	return gateway.routes()
+}
+
+func TestGatewayMethods(t *testing.T) {
+	routes := newTestGateway(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"read-only method with GET", http.MethodGet, "/api/get_status", "", http.StatusOK},
+		{"read-only method with POST", http.MethodPost, "/api/get_sessions", "{}", http.StatusOK},
+		{"pause with GET", http.MethodGet, "/api/pause?duration=30m", "", http.StatusMethodNotAllowed},
+		{"shutdown with GET", http.MethodGet, "/api/shutdown", "", http.StatusMethodNotAllowed},
+		{"update_config with GET", http.MethodGet, "/api/update_config", "", http.StatusMethodNotAllowed},
+		{"pause with POST", http.MethodPost, "/api/pause", `{"duration":"30m"}`, http.StatusOK},
+		{"unknown method", http.MethodGet, "/api/no_such_method", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed && rec.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("Allow = %q, want POST", rec.Header().Get("Allow"))
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONRPCVersion is the protocol version spoken on the socket. Messages
+// without a "jsonrpc" member are handled as the earlier Request/Response
+// envelope: one request per connection, answered with a Response.
+const JSONRPCVersion = "2.0"
+
+// NotificationEvent is the method of the notifications carrying events to
+// subscribers
+const NotificationEvent = "event"
+
+// Error codes. Codes from -32099 to -32000 are defined by the daemon.
+const (
+	CodeParseError     = -32700 // The message is not valid JSON
+	CodeInvalidRequest = -32600 // The message is not a valid request
+	CodeMethodNotFound = -32601 // The method does not exist
+	CodeInvalidParams  = -32602 // The params are missing or invalid
+	CodeInternalError  = -32603 // The daemon failed to handle the request
+	CodeServiceError   = -32000 // A service failed, e.g. the database
+	CodeUnsupported    = -32001 // The method is not available in this daemon
+	CodeUnauthorized   = -32002 // The client's token is missing or invalid
+	CodeForbidden      = -32003 // The client's scope doesn't allow the method
+)
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorData describes an error for programs
+type ErrorData struct {
+	Type   string `json:"type"`             // Name of the error code, e.g. "invalid_params"
+	Method string `json:"method,omitempty"` // Method of the failed request
+	Param  string `json:"param,omitempty"`  // Invalid parameter, if known
+}
+
+// errorTypes names the error codes in ErrorData
+var errorTypes = map[int]string{
+	CodeParseError:     "parse_error",
+	CodeInvalidRequest: "invalid_request",
+	CodeMethodNotFound: "method_not_found",
+	CodeInvalidParams:  "invalid_params",
+	CodeInternalError:  "internal_error",
+	CodeServiceError:   "service_error",
+	CodeUnsupported:    "unsupported",
+	CodeUnauthorized:   "unauthorized",
+	CodeForbidden:      "forbidden",
+}
+
+// newError builds an error with typed data
+func newError(code int, method, param, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Data:    &ErrorData{Type: errorTypes[code], Method: method, Param: param},
+	}
+}
+
+// jsonrpcRequest is a JSON-RPC request. Requests without an id are
+// notifications and get no response.
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonrpcResponse is a JSON-RPC response, holding either a result or an error
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// jsonrpcNotification is a message sent without expecting a response
+type jsonrpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// nullID is the id of responses to requests whose id could not be read
+var nullID = json.RawMessage("null")
+
+// errorResponseFor builds a JSON-RPC error response
+func errorResponseFor(id json.RawMessage, err *Error) *jsonrpcResponse {
+	if id == nil {
+		id = nullID
+	}
+	return &jsonrpcResponse{JSONRPC: JSONRPCVersion, ID: id, Error: err}
+}
+
+// toJSONRPC converts the response of a handler to a JSON-RPC response
+func toJSONRPC(id json.RawMessage, method string, resp Response) *jsonrpcResponse {
+	if !resp.Success {
+		err := resp.Err()
+		if err.Data != nil && err.Data.Method == "" {
+			err.Data.Method = method
+		}
+		return errorResponseFor(id, err)
+	}
+
+	result, err := json.Marshal(resp.Data)
+	if err != nil {
+		return errorResponseFor(id, newError(CodeInternalError, method, "", fmt.Sprintf("failed to encode result: %v", err)))
+	}
+	return &jsonrpcResponse{JSONRPC: JSONRPCVersion, ID: id, Result: result}
+}
+
+// parseRequest checks a JSON-RPC request and converts its params into the
+// form handlers take. It returns an error response if the request is invalid.
+func parseRequest(raw json.RawMessage) (*jsonrpcRequest, Request, *jsonrpcResponse) {
+	var msg jsonrpcRequest
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, Request{}, errorResponseFor(nil, newError(CodeInvalidRequest, "", "", "invalid request: "+err.Error()))
+	}
+	if msg.JSONRPC != JSONRPCVersion {
+		return nil, Request{}, errorResponseFor(msg.ID, newError(CodeInvalidRequest, msg.Method, "", fmt.Sprintf("jsonrpc must be %q, got %q", JSONRPCVersion, msg.JSONRPC)))
+	}
+	if msg.Method == "" {
+		return nil, Request{}, errorResponseFor(msg.ID, newError(CodeInvalidRequest, "", "", "method is required"))
+	}
+
+	req := Request{Method: msg.Method, Params: map[string]interface{}{}}
+	if len(msg.Params) > 0 && !bytes.Equal(msg.Params, nullID) {
+		if err := json.Unmarshal(msg.Params, &req.Params); err != nil {
+			return nil, Request{}, errorResponseFor(msg.ID, newError(CodeInvalidParams, msg.Method, "", "params must be an object"))
+		}
+	}
+	return &msg, req, nil
+}
+
+// messageKind tells how a message on the socket is framed
+type messageKind int
+
+const (
+	kindInvalid messageKind = iota
+	kindLegacy              // The earlier Request envelope
+	kindSingle              // A JSON-RPC request or notification
+	kindBatch               // An array of JSON-RPC requests
+)
+
+// classify tells how a message is framed from its first character and
+// whether it has a "jsonrpc" member
+func classify(raw json.RawMessage) messageKind {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return kindInvalid
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return kindBatch
+	case '{':
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return kindInvalid
+		}
+		if _, ok := probe["jsonrpc"]; ok {
+			return kindSingle
+		}
+		return kindLegacy
+	default:
+		return kindInvalid
+	}
+}
+
+// legacyCode guesses the code of an error reported by a daemon that only
+// speaks the earlier envelope
+func legacyCode(message string) int {
+	if strings.HasPrefix(message, "unknown method") {
+		return CodeMethodNotFound
+	}
+	return CodeServiceError
+}
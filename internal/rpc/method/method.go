@@ -0,0 +1,38 @@
+// Package method names the RPC methods served by the daemon. The server,
+// the services registering their methods and the client share these names.
+package method
+
+// Method names
+const (
+	Ping                   = "ping"
+	GetStatus              = "get_status"
+	GetActivityLogs        = "get_activity_logs"
+	GetConfig              = "get_config"
+	UpdateConfig           = "update_config"
+	DescribeConfig         = "describe_config"
+	Shutdown               = "shutdown"
+	GetGamificationStatus  = "get_gamification_status"
+	GetGamificationDetails = "get_gamification_details"
+	GetSessions            = "get_sessions"
+	CreateProject          = "create_project"
+	GetProjects            = "get_projects"
+	AddProjectRule         = "add_project_rule"
+	RemoveProjectRule      = "remove_project_rule"
+	GetProjectRules        = "get_project_rules"
+	AssignProject          = "assign_project"
+	TagActivity            = "tag_activity"
+	GetProjectReport       = "get_project_report"
+	AddManualEntry         = "add_manual_entry"
+	GetManualEntries       = "get_manual_entries"
+	GetApps                = "get_apps"
+	RecategorizeApps       = "recategorize_apps"
+	TestPrivacy            = "test_privacy"
+	Pause                  = "pause"
+	Resume                 = "resume"
+	Subscribe              = "subscribe"
+	CreateToken            = "create_token"
+	ListTokens             = "list_tokens"
+	RevokeToken            = "revoke_token"
+	Discover               = "rpc.discover"     // Describes the methods served
+	Authenticate           = "rpc.authenticate" // Switches the connection to a token's scope
+)
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package rpc
+
+import "net"
+
+// peerUID reports that peer credentials are not available on this platform
+func peerUID(conn *net.UnixConn) (int, error) {
+	return 0, errPeerCredUnsupported
+}
@@ -2,31 +2,77 @@ package rpc
 
 import (
 	"time"
+	
+	"shien/internal/auth"
+	"shien/internal/rpc/method"
+	"shien/internal/service"
 )
 
-// Request represents an RPC request
+// Request represents an RPC request. It is also the envelope of clients
+// predating JSON-RPC, which send it without a "jsonrpc" member.
 type Request struct {
 	Method string                 `json:"method"`
 	Params map[string]interface{} `json:"params"`
 }
 
-// Response represents an RPC response
+// Response represents the outcome of an RPC request. Handlers return it,
+// Client.Call reports results in it, and clients predating JSON-RPC
+// receive it as is.
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success   bool        `json:"success"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Code      int         `json:"code,omitempty"`       // JSON-RPC error code when Success is false
+	ErrorData *ErrorData  `json:"error_data,omitempty"` // Details of the error for programs
 }
 
-// Methods
+// Err returns the error of a failed response
+func (r Response) Err() *Error {
+	code := r.Code
+	if code == 0 {
+		code = CodeServiceError
+	}
+	
+	data := ErrorData{Type: errorTypes[code]}
+	if r.ErrorData != nil {
+		data = *r.ErrorData
+	}
+	return &Error{Code: code, Message: r.Error, Data: &data}
+}
+
+// Methods, named in package method
 const (
-	MethodPing            = "ping"
-	MethodGetStatus       = "get_status"
-	MethodGetActivityLogs = "get_activity_logs"
-	MethodGetConfig       = "get_config"
-	MethodUpdateConfig    = "update_config"
-	MethodShutdown        = "shutdown"
-	MethodGetGamificationStatus = "get_gamification_status"
-	MethodGetGamificationDetails = "get_gamification_details"
+	MethodPing                   = method.Ping
+	MethodGetStatus              = method.GetStatus
+	MethodGetActivityLogs        = method.GetActivityLogs
+	MethodGetConfig              = method.GetConfig
+	MethodUpdateConfig           = method.UpdateConfig
+	MethodDescribeConfig         = method.DescribeConfig
+	MethodShutdown               = method.Shutdown
+	MethodGetGamificationStatus  = method.GetGamificationStatus
+	MethodGetGamificationDetails = method.GetGamificationDetails
+	MethodGetSessions            = method.GetSessions
+	MethodCreateProject          = method.CreateProject
+	MethodGetProjects            = method.GetProjects
+	MethodAddProjectRule         = method.AddProjectRule
+	MethodRemoveProjectRule      = method.RemoveProjectRule
+	MethodGetProjectRules        = method.GetProjectRules
+	MethodAssignProject          = method.AssignProject
+	MethodTagActivity            = method.TagActivity
+	MethodGetProjectReport       = method.GetProjectReport
+	MethodAddManualEntry         = method.AddManualEntry
+	MethodGetManualEntries       = method.GetManualEntries
+	MethodGetApps                = method.GetApps
+	MethodRecategorizeApps       = method.RecategorizeApps
+	MethodTestPrivacy            = method.TestPrivacy
+	MethodPause                  = method.Pause
+	MethodResume                 = method.Resume
+	MethodSubscribe              = method.Subscribe
+	MethodCreateToken            = method.CreateToken
+	MethodListTokens             = method.ListTokens
+	MethodRevokeToken            = method.RevokeToken
+	MethodDiscover               = method.Discover
+	MethodAuthenticate           = method.Authenticate
 )
 
 // Status represents daemon status
@@ -34,6 +80,32 @@ type Status struct {
 	Running   bool      `json:"running"`
 	StartedAt time.Time `json:"started_at"`
 	Version   string    `json:"version"`
+	Paused      bool       `json:"paused"`
+	PausedSince *time.Time `json:"paused_since,omitempty"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"` // nil when paused until resumed
+}
+
+// ConfigUpdate is the result of update_config
+type ConfigUpdate = service.ConfigUpdate
+
+// Subscription acknowledges a subscribe request. It is followed on the same
+// connection by one "event" notification per event, whose params are an
+// events.Event (bare events.Event values for the earlier envelope).
+type Subscription struct {
+	Topics []string `json:"topics"` // Topic filters, empty for all topics
+}
+
+// Authentication is the result of rpc.authenticate
+type Authentication struct {
+	Token string     `json:"token"` // Name of the token
+	Scope auth.Scope `json:"scope"`
+}
+
+// CreatedToken is the result of create_token. The secret is not shown again.
+type CreatedToken struct {
+	Name   string     `json:"name"`
+	Scope  auth.Scope `json:"scope"`
+	Secret string     `json:"secret"`
 }
 
 // ActivityLogFilter for querying logs
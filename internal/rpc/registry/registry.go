@@ -0,0 +1,366 @@
+// Package registry holds the methods served over RPC. Each method declares
+// its params as a struct, which the registry decodes and validates before
+// calling the method, and describes for clients.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownMethod is returned by Call for methods that are not registered
+var ErrUnknownMethod = errors.New("unknown method")
+
+// ParamError reports params that are missing or invalid
+type ParamError struct {
+	Param string // Name of the param, empty if not about a single param
+	Err   error
+}
+
+// Error returns the message of the underlying error
+func (e *ParamError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// Validator is implemented by params with checks beyond their types.
+// Validate is called after the params are decoded.
+type Validator interface {
+	Validate() error
+}
+
+// None is the params of methods that take none, and the result of methods
+// that return nothing
+type None struct{}
+
+// MarshalJSON encodes None as null
+func (None) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// Method describes a registered method for clients
+type Method struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Params      []Param `json:"params"`
+	Result      string  `json:"result"`              // Type of the result
+	ReadOnly    bool    `json:"read_only,omitempty"` // Changes nothing, so read-only clients may call it
+}
+
+// Option sets a property of a method when it is registered
+type Option func(*Method)
+
+// ReadOnly marks a method that changes nothing
+func ReadOnly(m *Method) {
+	m.ReadOnly = true
+}
+
+// Param describes a param of a method
+type Param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Registry holds methods by name
+type Registry struct {
+	methods map[string]*method
+	mu      sync.RWMutex
+}
+
+// method is a registered method
+type method struct {
+	info Method
+	call func(params json.RawMessage) (interface{}, error)
+}
+
+// New creates an empty registry
+func New() *Registry {
+	return &Registry{methods: make(map[string]*method)}
+}
+
+// Register adds a method. P is a struct whose exported fields are the
+// params, named by their json tag. Fields tagged `rpc:"required"` must be
+// given, and the doc tag describes a param. Params that are not declared
+// are rejected. Register panics if the name is taken or P is not a struct.
+func Register[P, R any](r *Registry, name, description string, handler func(P) (R, error), options ...Option) {
+	paramsType := reflect.TypeOf((*P)(nil)).Elem()
+	if paramsType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("registry: params of %s must be a struct, got %s", name, paramsType))
+	}
+
+	m := &method{
+		info: Method{
+			Name:        name,
+			Description: description,
+			Params:      describeParams(paramsType),
+			Result:      typeName(reflect.TypeOf((*R)(nil)).Elem()),
+		},
+		call: func(raw json.RawMessage) (interface{}, error) {
+			var params P
+			if err := decodeParams(raw, &params); err != nil {
+				return nil, err
+			}
+			return handler(params)
+		},
+	}
+	for _, option := range options {
+		option(&m.info)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.methods[name]; exists {
+		panic(fmt.Sprintf("registry: method %s registered twice", name))
+	}
+	r.methods[name] = m
+}
+
+// Call decodes params, a JSON object or empty, and calls the named method
+// with them. Invalid params are reported as *ParamError.
+func (r *Registry) Call(name string, params json.RawMessage) (interface{}, error) {
+	r.mu.RLock()
+	m, ok := r.methods[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, name)
+	}
+	return m.call(params)
+}
+
+// Lookup returns the description of a method
+func (r *Registry) Lookup(name string) (Method, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.methods[name]
+	if !ok {
+		return Method{}, false
+	}
+	return m.info, true
+}
+
+// Methods describes every method, sorted by name
+func (r *Registry) Methods() []Method {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]Method, 0, len(r.methods))
+	for _, m := range r.methods {
+		methods = append(methods, m.info)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+	return methods
+}
+
+// field is a param of a params struct
+type field struct {
+	index    []int
+	param    Param
+	typ      reflect.Type
+	required bool
+}
+
+// paramFields returns the params of a struct, including those of embedded
+// structs
+func paramFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range paramFields(f.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		required := f.Tag.Get("rpc") == "required"
+		fields = append(fields, field{
+			index: []int{i},
+			param: Param{
+				Name:        name,
+				Type:        typeName(f.Type),
+				Required:    required,
+				Description: f.Tag.Get("doc"),
+			},
+			typ:      f.Type,
+			required: required,
+		})
+	}
+	return fields
+}
+
+// describeParams lists the params of a params struct
+func describeParams(t reflect.Type) []Param {
+	params := []Param{}
+	for _, f := range paramFields(t) {
+		params = append(params, f.param)
+	}
+	return params
+}
+
+// decodeParams decodes params into the struct pointed to by out, one param
+// at a time so errors name the param, then validates them
+func decodeParams(raw json.RawMessage, out interface{}) error {
+	var values map[string]json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return &ParamError{Err: fmt.Errorf("params must be an object")}
+		}
+	}
+
+	// Params decoding themselves handle all of their values
+	if unmarshaler, ok := out.(json.Unmarshaler); ok {
+		if values != nil {
+			if err := unmarshaler.UnmarshalJSON(raw); err != nil {
+				return &ParamError{Err: err}
+			}
+		}
+	} else {
+		target := reflect.ValueOf(out).Elem()
+		fields := paramFields(target.Type())
+		known := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			known[f.param.Name] = true
+
+			value, ok := values[f.param.Name]
+			if !ok || string(value) == "null" {
+				if f.required {
+					return &ParamError{Param: f.param.Name, Err: fmt.Errorf("%s is required", f.param.Name)}
+				}
+				continue
+			}
+
+			if err := json.Unmarshal(value, target.FieldByIndex(f.index).Addr().Interface()); err != nil {
+				return &ParamError{Param: f.param.Name, Err: invalidValue(f, value, err)}
+			}
+		}
+
+		for _, name := range sortedKeys(values) {
+			if !known[name] {
+				return &ParamError{Param: name, Err: fmt.Errorf("unknown param %q", name)}
+			}
+		}
+	}
+
+	if validator, ok := out.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			var paramErr *ParamError
+			if errors.As(err, &paramErr) {
+				return paramErr
+			}
+			return &ParamError{Err: err}
+		}
+	}
+	return nil
+}
+
+// invalidValue describes a value that does not decode into its param
+func invalidValue(f field, value json.RawMessage, err error) error {
+	// Durations explain what is wrong themselves
+	if f.typ == reflect.TypeOf(Duration(0)) {
+		return fmt.Errorf("invalid %s: %w", f.param.Name, err)
+	}
+	return fmt.Errorf("invalid %s: expected %s, got %s", f.param.Name, f.param.Type, value)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName describes a Go type in JSON terms for clients
+func typeName(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return "time (RFC 3339)"
+	case reflect.TypeOf(Duration(0)):
+		return "duration"
+	case reflect.TypeOf(None{}):
+		return "null"
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array of " + typeName(t.Elem())
+	case reflect.Interface:
+		return "any"
+	case reflect.Struct:
+		if t.Name() != "" {
+			return t.Name()
+		}
+		return "object"
+	default:
+		return "object"
+	}
+}
+
+// Duration is a param given as a string like "5m" or "1h30m". An empty
+// string is zero.
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string, which must not be negative
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("expected a duration like \"5m\", got %s", data)
+	}
+	if text == "" {
+		*d = 0
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(text)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("expected a duration like \"5m\", got %q", text)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Duration returns the value as a time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
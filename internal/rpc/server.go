@@ -2,24 +2,41 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net"
 	"os"
 	"sync"
 	"time"
 	
+	"shien/internal/auth"
+	"shien/internal/events"
 	"shien/internal/paths"
+	"shien/internal/rpc/registry"
 	"shien/internal/service"
 )
 
+// idleTimeout is how long a connection may wait for its next request. The
+// client reopens connections the daemon closed, so it only bounds the
+// clients that connect and never send anything.
+const idleTimeout = 2 * time.Minute
+
 // Server handles RPC requests
 type Server struct {
-	socketPath string
-	listener   net.Listener
-	services   *service.Services
-	startedAt  time.Time
-	mu         sync.RWMutex
-	shutdown   chan struct{}
+	socketPath   string
+	listener     net.Listener
+	services     *service.Services
+	methods      *registry.Registry
+	tokens       *auth.Store    // Client tokens
+	audit        *auth.AuditLog // Administrative calls
+	startedAt    time.Time
+	idleTimeout  time.Duration // Before the next request of a connection
+	mu           sync.RWMutex
+	shutdown     chan struct{}
+	stopOnce     sync.Once
+	onShutdown   func() // Called when a client requests shutdown
+	shutdownOnce sync.Once
 }
 
 // NewServer creates a new RPC server
@@ -29,12 +46,31 @@ func NewServer(services *service.Services) (*Server, error) {
 	// Remove existing socket file if it exists
 	os.Remove(socketPath)
 	
-	return &Server{
-		socketPath: socketPath,
-		services:   services,
-		startedAt:  time.Now(),
-		shutdown:   make(chan struct{}),
-	}, nil
+	tokens := auth.NewStore(paths.TokensFile())
+	if err := tokens.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load client tokens: %w", err)
+	}
+	
+	s := &Server{
+		socketPath:  socketPath,
+		services:    services,
+		methods:     registry.New(),
+		tokens:      tokens,
+		audit:       auth.NewAuditLog(paths.AuditLogFile()),
+		startedAt:   time.Now(),
+		idleTimeout: idleTimeout,
+		shutdown:    make(chan struct{}),
+	}
+	s.registerMethods()
+	s.registerAuthMethods()
+	services.RegisterMethods(s.methods)
+	return s, nil
+}
+
+// Methods returns the registry of the methods served, with which more
+// methods can be registered
+func (s *Server) Methods() *registry.Registry {
+	return s.methods
 }
 
 // Start starts the RPC server
@@ -56,13 +92,23 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop stops the RPC server
+// SetShutdownHandler sets the function that stops the daemon when a client
+// requests a shutdown. Without one, shutdown requests are rejected.
+func (s *Server) SetShutdownHandler(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = fn
+}
+
+// Stop stops the RPC server. It is safe to call more than once.
 func (s *Server) Stop() error {
-	close(s.shutdown)
-	if s.listener != nil {
-		s.listener.Close()
-	}
-	os.Remove(s.socketPath)
+	s.stopOnce.Do(func() {
+		close(s.shutdown)
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		os.Remove(s.socketPath)
+	})
 	return nil
 }
 
@@ -87,14 +133,123 @@ func (s *Server) acceptConnections() {
 	}
 }
 
+// handleConnection serves JSON-RPC messages until the client disconnects.
+// Requests in the earlier envelope are answered once and the connection
+// closed, as before. Only processes of the daemon's user are served.
 func (s *Server) handleConnection(conn net.Conn) {
 	defer conn.Close()
 	
+	c, err := s.identify(conn)
+	if err != nil {
+		log.Printf("Refused RPC connection: %v", err)
+		return
+	}
+	
 	decoder := json.NewDecoder(conn)
 	encoder := json.NewEncoder(conn)
 	
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			// The stream can't be resynchronized after invalid JSON
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				encoder.Encode(errorResponseFor(nil, newError(CodeParseError, "", "", "parse error: "+err.Error())))
+			}
+			return
+		}
+		
+		switch classify(raw) {
+		case kindLegacy:
+			s.handleLegacy(c, conn, encoder, raw)
+			return
+			
+		case kindBatch:
+			if err := s.handleBatch(c, encoder, raw); err != nil {
+				return
+			}
+			
+		case kindSingle:
+			msg, req, invalid := parseRequest(raw)
+			if invalid != nil {
+				if encoder.Encode(invalid) != nil {
+					return
+				}
+				continue
+			}
+			
+			// Subscriptions turn the connection into a stream of events,
+			// which stays open as long as the client wants
+			if req.Method == MethodSubscribe {
+				conn.SetReadDeadline(time.Time{})
+				s.stream(c, req, watchDisconnect(conn), func(resp Response) error {
+					return encoder.Encode(toJSONRPC(msg.ID, req.Method, resp))
+				}, func(event events.Event) error {
+					return encoder.Encode(jsonrpcNotification{JSONRPC: JSONRPCVersion, Method: NotificationEvent, Params: event})
+				})
+				return
+			}
+			
+			resp := s.call(c, req)
+			if msg.ID != nil {
+				if err := encoder.Encode(toJSONRPC(msg.ID, req.Method, resp)); err != nil {
+					return
+				}
+			}
+			s.afterResponse(req, resp)
+			
+		default:
+			if encoder.Encode(errorResponseFor(nil, newError(CodeInvalidRequest, "", "", "request must be an object or an array"))) != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleBatch answers a batch of requests with an array of responses, one
+// for each request that has an id
+func (s *Server) handleBatch(c *caller, encoder *json.Encoder, raw json.RawMessage) error {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(raw, &messages); err != nil || len(messages) == 0 {
+		return encoder.Encode(errorResponseFor(nil, newError(CodeInvalidRequest, "", "", "batch must be a non-empty array of requests")))
+	}
+	
+	var responses []*jsonrpcResponse
+	var handled []Request
+	var results []Response
+	for _, message := range messages {
+		msg, req, invalid := parseRequest(message)
+		if invalid != nil {
+			responses = append(responses, invalid)
+			continue
+		}
+		
+		resp := s.call(c, req)
+		if msg.ID != nil {
+			responses = append(responses, toJSONRPC(msg.ID, req.Method, resp))
+		}
+		handled = append(handled, req)
+		results = append(results, resp)
+	}
+	
+	// A batch of notifications gets no response at all
+	if len(responses) > 0 {
+		if err := encoder.Encode(responses); err != nil {
+			return err
+		}
+	}
+	for i, req := range handled {
+		s.afterResponse(req, results[i])
+	}
+	return nil
+}
+
+// handleLegacy answers a request in the envelope used before JSON-RPC
+func (s *Server) handleLegacy(c *caller, conn net.Conn, encoder *json.Encoder, raw json.RawMessage) {
 	var req Request
-	if err := decoder.Decode(&req); err != nil {
+	if err := json.Unmarshal(raw, &req); err != nil {
 		encoder.Encode(Response{
 			Success: false,
 			Error:   "invalid request format",
@@ -102,161 +257,88 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 	
-	response := s.handleRequest(req)
+	// Subscriptions keep the connection open to stream events
+	if req.Method == MethodSubscribe {
+		conn.SetReadDeadline(time.Time{})
+		s.stream(c, req, watchDisconnect(conn), func(resp Response) error {
+			return encoder.Encode(resp)
+		}, func(event events.Event) error {
+			return encoder.Encode(event)
+		})
+		return
+	}
+	
+	response := s.call(c, req)
 	encoder.Encode(response)
+	s.afterResponse(req, response)
+}
+
+// afterResponse carries out what must wait until the client got its
+// response: shutting down
+func (s *Server) afterResponse(req Request, resp Response) {
+	if req.Method != MethodShutdown || !resp.Success {
+		return
+	}
+	
+	s.mu.RLock()
+	onShutdown := s.onShutdown
+	s.mu.RUnlock()
+	s.shutdownOnce.Do(func() {
+		go onShutdown()
+	})
 }
 
+// handleRequest calls the registered method of a request. Requests from
+// clients go through call, which checks the client's scope.
 func (s *Server) handleRequest(req Request) Response {
-	switch req.Method {
-	case MethodPing:
-		return Response{
-			Success: true,
-			Data:    "pong",
-		}
-		
-	case MethodGetStatus:
-		return Response{
-			Success: true,
-			Data: Status{
-				Running:   true,
-				StartedAt: s.startedAt,
-				Version:   "1.0.0",
-			},
-		}
-		
-	case MethodGetActivityLogs:
-		var filter ActivityLogFilter
-		if fromStr, ok := req.Params["from"].(string); ok {
-			filter.From, _ = time.Parse(time.RFC3339, fromStr)
-		}
-		if toStr, ok := req.Params["to"].(string); ok {
-			filter.To, _ = time.Parse(time.RFC3339, toStr)
-		}
-		
-		logs, err := s.services.Activity.GetActivityLogs(filter.From, filter.To)
-		if err != nil {
-			return Response{
-				Success: false,
-				Error:   err.Error(),
-			}
-		}
-		
-		return Response{
-			Success: true,
-			Data:    logs,
-		}
-		
-	case MethodGetConfig:
-		return Response{
-			Success: true,
-			Data:    s.services.Config.GetConfig(),
-		}
-		
-	case MethodGetGamificationStatus:
-		userID := "default_user" // Default for now
-		if id, ok := req.Params["user_id"].(string); ok {
-			userID = id
-		}
-		
-		status, err := s.services.Gamification.GetEffectiveStatus(userID)
-		if err != nil {
-			return Response{
-				Success: false,
-				Error:   err.Error(),
-			}
-		}
-		
-		// Calculate next level experience requirement
-		config := s.services.Gamification.GetConfig()
-		nextLevelExp := config.ExpForLevel(status.Level + 1)
-		
-		return Response{
-			Success: true,
-			Data: GamificationStatus{
-				UserID:        status.UserID,
-				Level:         status.Level,
-				Experience:    status.Experience,
-				TotalExp:      status.TotalExp,
-				NextLevelExp:  nextLevelExp,
-				Focus:         status.Focus,
-				Productivity:  status.Productivity,
-				Creativity:    status.Creativity,
-				Stamina:       status.Stamina,
-				Knowledge:     status.Knowledge,
-				Collaboration: status.Collaboration,
-				UpdatedAt:     status.UpdatedAt,
-			},
-		}
-		
-	case MethodGetGamificationDetails:
-		userID := "default_user" // Default for now
-		if id, ok := req.Params["user_id"].(string); ok {
-			userID = id
-		}
-		
-		status, err := s.services.Gamification.GetEffectiveStatus(userID)
-		if err != nil {
-			return Response{
-				Success: false,
-				Error:   err.Error(),
-			}
-		}
-		
-		// Get modifiers
-		modifiers, err := s.services.Gamification.GetModifiers(userID)
-		if err != nil {
-			return Response{
-				Success: false,
-				Error:   err.Error(),
-			}
-		}
-		
-		// Convert modifiers to RPC format
-		rpcModifiers := make([]AttributeModifier, len(modifiers))
-		for i, mod := range modifiers {
-			rpcModifiers[i] = AttributeModifier{
-				Attribute: mod.Attribute,
-				Value:     mod.Value,
-				Reason:    mod.Reason,
-				ExpiresAt: mod.ExpiresAt,
-			}
-		}
-		
-		// Get today's app usage
-		today := time.Now()
-		startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-		appUsage, _ := s.services.Activity.GetAppUsageSummary(startOfDay, today)
-		
-		// Calculate next level experience requirement
-		config := s.services.Gamification.GetConfig()
-		nextLevelExp := config.ExpForLevel(status.Level + 1)
-		
-		return Response{
-			Success: true,
-			Data: GamificationDetails{
-				Status: &GamificationStatus{
-					UserID:        status.UserID,
-					Level:         status.Level,
-					Experience:    status.Experience,
-					TotalExp:      status.TotalExp,
-					NextLevelExp:  nextLevelExp,
-					Focus:         status.Focus,
-					Productivity:  status.Productivity,
-					Creativity:    status.Creativity,
-					Stamina:       status.Stamina,
-					Knowledge:     status.Knowledge,
-					Collaboration: status.Collaboration,
-					UpdatedAt:     status.UpdatedAt,
-				},
-				Modifiers:  rpcModifiers,
-				RecentApps: appUsage,
-			},
-		}
-		
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return invalidParams("", fmt.Errorf("params must be an object"))
+	}
+	
+	result, err := s.methods.Call(req.Method, params)
+	if err != nil {
+		return errorResponse(err)
+	}
+	
+	return Response{
+		Success: true,
+		Data:    result,
+	}
+}
+
+// errorResponse builds a failed response from an error returned by a
+// method. Errors caused by the request are reported as invalid params.
+func errorResponse(err error) Response {
+	var paramErr *registry.ParamError
+	var invalid *service.InvalidInputError
+	switch {
+	case errors.As(err, &paramErr):
+		return invalidParams(paramErr.Param, err)
+	case errors.Is(err, registry.ErrUnknownMethod):
+		return failedResponse(CodeMethodNotFound, "", err)
+	case errors.As(err, &invalid):
+		return invalidParams("", err)
+	case errors.Is(err, errShutdownUnsupported):
+		return failedResponse(CodeUnsupported, "", err)
+	case errors.Is(err, errSubscribeBatched):
+		return failedResponse(CodeInvalidRequest, "", err)
 	default:
-		return Response{
-			Success: false,
-			Error:   fmt.Sprintf("unknown method: %s", req.Method),
-		}
+		return failedResponse(CodeServiceError, "", err)
 	}
-}
\ No newline at end of file
+}
+
+// invalidParams builds a failed response for a missing or invalid parameter
+func invalidParams(param string, err error) Response {
+	return failedResponse(CodeInvalidParams, param, err)
+}
+
+// failedResponse builds a failed response with an error code
+func failedResponse(code int, param string, err error) Response {
+	return Response{
+		Success:   false,
+		Error:     err.Error(),
+		Code:      code,
+		ErrorData: &ErrorData{Type: errorTypes[code], Param: param},
+	}
+}
@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"shien/internal/auth"
+	"shien/internal/rpc/registry"
+)
+
+// Transports clients reach the methods over, as recorded in the audit log
+const (
+	transportSocket = "socket"
+	transportHTTP   = "http"
+)
+
+var (
+	// errPeerCredUnsupported reports that the UID of socket peers can't be
+	// read on this platform
+	errPeerCredUnsupported = errors.New("peer credentials are not supported on this platform")
+
+	// errAuthenticateHandled marks rpc.authenticate, which the connection
+	// handles before the registry is asked
+	errAuthenticateHandled = errors.New("rpc.authenticate is handled by the connection")
+)
+
+// caller is the client a request comes from
+type caller struct {
+	transport string
+	uid       int        // UID of the client process, -1 when unknown
+	token     string     // Name of the token the client authenticated with
+	secret    string     // Secret of that token, looked up on each call so revoking it takes effect at once
+	scope     auth.Scope // Fixed scope, e.g. of the gateway token; empty to use the token's or the default
+}
+
+// AuthenticateParams is the params of rpc.authenticate
+type AuthenticateParams struct {
+	Token string `json:"token" rpc:"required" doc:"Secret of a client token"`
+}
+
+// CreateTokenParams is the params of create_token
+type CreateTokenParams struct {
+	Name  string `json:"name" rpc:"required" doc:"Name of the token"`
+	Scope string `json:"scope" doc:"\"read\" or \"admin\", default \"read\""`
+}
+
+// TokenParams is the params of methods about one token
+type TokenParams struct {
+	Name string `json:"name" rpc:"required" doc:"Name of the token"`
+}
+
+// registerAuthMethods registers the methods managing client tokens
+func (s *Server) registerAuthMethods() {
+	// Authentication changes the scope of the connection, so it is handled
+	// by call; the method is registered to be described
+	registry.Register(s.methods, MethodAuthenticate, "Use the scope of a client token for the rest of the connection",
+		func(AuthenticateParams) (Authentication, error) {
+			return Authentication{}, errAuthenticateHandled
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodCreateToken, "Create a client token; its secret is only shown now",
+		func(p CreateTokenParams) (CreatedToken, error) {
+			scope := auth.ScopeRead
+			if p.Scope != "" {
+				parsed, err := auth.ParseScope(p.Scope)
+				if err != nil {
+					return CreatedToken{}, &registry.ParamError{Param: "scope", Err: err}
+				}
+				scope = parsed
+			}
+
+			secret, err := s.tokens.Create(p.Name, scope)
+			if errors.Is(err, auth.ErrTokenExists) {
+				return CreatedToken{}, &registry.ParamError{Param: "name", Err: err}
+			}
+			if err != nil {
+				return CreatedToken{}, err
+			}
+			return CreatedToken{Name: p.Name, Scope: scope, Secret: secret}, nil
+		})
+	registry.Register(s.methods, MethodListTokens, "List the client tokens",
+		func(registry.None) ([]auth.Token, error) {
+			return s.tokens.List(), nil
+		})
+	registry.Register(s.methods, MethodRevokeToken, "Revoke a client token",
+		func(p TokenParams) (string, error) {
+			if err := s.tokens.Revoke(p.Name); err != nil {
+				if errors.Is(err, auth.ErrUnknownToken) {
+					return "", &registry.ParamError{Param: "name", Err: err}
+				}
+				return "", err
+			}
+			return "revoked", nil
+		})
+}
+
+// identify returns the caller of a socket connection. Connections from
+// processes of other users are refused.
+func (s *Server) identify(conn net.Conn) (*caller, error) {
+	c := &caller{transport: transportSocket, uid: -1}
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return c, nil
+	}
+	uid, err := peerUID(unixConn)
+	switch {
+	case errors.Is(err, errPeerCredUnsupported):
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read peer credentials: %w", err)
+	case uid != os.Getuid():
+		return nil, fmt.Errorf("peer runs as UID %d, not %d", uid, os.Getuid())
+	}
+
+	c.uid = uid
+	return c, nil
+}
+
+// scopeOf returns what a caller may call: the fixed scope, the scope of its
+// token, or rpc_default_scope for clients without a token
+func (s *Server) scopeOf(c *caller) auth.Scope {
+	if c.scope != "" {
+		return c.scope
+	}
+	if c.secret != "" {
+		token, ok := s.tokens.Lookup(c.secret)
+		if !ok {
+			return auth.ScopeNone
+		}
+		return token.Scope
+	}
+	if scope := auth.Scope(s.services.Config.GetConfig().RPCDefaultScope); scope != "" {
+		return scope
+	}
+	return auth.ScopeAdmin
+}
+
+// call handles a request from a caller: rpc.authenticate, or a method the
+// caller's scope allows. Administrative calls and denied calls are audited.
+func (s *Server) call(c *caller, req Request) Response {
+	if req.Method == MethodAuthenticate {
+		return s.authenticate(c, req)
+	}
+
+	if denied := s.authorize(c, req); denied != nil {
+		return *denied
+	}
+
+	resp := s.handleRequest(req)
+	if method, ok := s.methods.Lookup(req.Method); ok && !method.ReadOnly {
+		s.record(c, req, resp)
+	}
+	return resp
+}
+
+// authorize returns a failed response if the caller's scope doesn't allow
+// the method. Unknown methods are left to be reported as such.
+func (s *Server) authorize(c *caller, req Request) *Response {
+	method, ok := s.methods.Lookup(req.Method)
+	if !ok || s.scopeOf(c).Allows(method.ReadOnly) {
+		return nil
+	}
+
+	required := auth.ScopeAdmin
+	if method.ReadOnly {
+		required = auth.ScopeRead
+	}
+	resp := failedResponse(CodeForbidden, "", fmt.Errorf("%s requires the %s scope", req.Method, required))
+	s.record(c, req, resp)
+	return &resp
+}
+
+// authenticate switches the caller to the scope of a token
+func (s *Server) authenticate(c *caller, req Request) Response {
+	secret, _ := req.Params["token"].(string)
+	if secret == "" {
+		return invalidParams("token", errors.New("token is required"))
+	}
+
+	token, ok := s.tokens.Lookup(secret)
+	if !ok {
+		// The params hold the secret, which is never written down
+		resp := failedResponse(CodeUnauthorized, "token", errors.New("invalid token"))
+		s.record(c, Request{Method: req.Method}, resp)
+		return resp
+	}
+
+	c.token = token.Name
+	c.secret = secret
+	return Response{
+		Success: true,
+		Data:    Authentication{Token: token.Name, Scope: token.Scope},
+	}
+}
+
+// record writes a call to the audit log
+func (s *Server) record(c *caller, req Request, resp Response) {
+	entry := auth.AuditEntry{
+		Method:    req.Method,
+		Transport: c.transport,
+		Token:     c.token,
+		Params:    req.Params,
+		Outcome:   auth.OutcomeOK,
+	}
+	if c.uid >= 0 {
+		uid := c.uid
+		entry.UID = &uid
+	}
+	if !resp.Success {
+		entry.Outcome = auth.OutcomeFailed
+		if resp.Code == CodeUnauthorized || resp.Code == CodeForbidden {
+			entry.Outcome = auth.OutcomeDenied
+		}
+		entry.Error = resp.Error
+	}
+
+	if err := s.audit.Record(entry); err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
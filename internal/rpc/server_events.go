@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"shien/internal/events"
+)
+
+// stream sends events to a subscriber until disconnected is closed or the
+// server stops. reply answers the subscribe request and send delivers an
+// event, in the framing the client used.
+func (s *Server) stream(c *caller, req Request, disconnected <-chan struct{}, reply func(Response) error, send func(events.Event) error) {
+	if denied := s.authorize(c, req); denied != nil {
+		reply(*denied)
+		return
+	}
+
+	topics, err := subscriptionTopics(req.Params)
+	if err != nil {
+		reply(invalidParams("topics", err))
+		return
+	}
+
+	sub := s.services.Events.Subscribe(events.DefaultBuffer, topics...)
+	defer sub.Close()
+
+	if err := reply(Response{
+		Success: true,
+		Data:    Subscription{Topics: topics},
+	}); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// watchDisconnect returns a channel closed when a subscriber on the socket
+// disconnects. Subscribers send nothing more, so a read returns only then.
+func watchDisconnect(conn net.Conn) <-chan struct{} {
+	disconnected := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(disconnected)
+	}()
+	return disconnected
+}
+
+// subscriptionTopics reads the topic filters of a subscribe request, given
+// as a list or a comma-separated string. Filters must match a known topic.
+func subscriptionTopics(params map[string]interface{}) ([]string, error) {
+	var topics []string
+	switch value := params["topics"].(type) {
+	case nil:
+	case string:
+		for _, topic := range strings.Split(value, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			topic, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("topics must be strings")
+			}
+			topics = append(topics, topic)
+		}
+	default:
+		return nil, fmt.Errorf("topics must be a list of strings")
+	}
+
+	for _, filter := range topics {
+		if !matchesAnyTopic(filter) {
+			return nil, fmt.Errorf("unknown topic %q (topics: %s)", filter, strings.Join(events.Topics(), ", "))
+		}
+	}
+	return topics, nil
+}
+
+// matchesAnyTopic reports whether a filter matches a topic the daemon publishes
+func matchesAnyTopic(filter string) bool {
+	for _, topic := range events.Topics() {
+		if events.Match(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
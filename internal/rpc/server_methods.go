@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"shien/internal/rpc/registry"
+	"shien/internal/service"
+)
+
+var (
+	// errShutdownUnsupported rejects shutdown requests when the daemon has
+	// no shutdown handler
+	errShutdownUnsupported = errors.New("shutdown is not supported by this daemon")
+
+	// errSubscribeBatched rejects subscribe requests that are not alone on
+	// their connection
+	errSubscribeBatched = errors.New("subscribe cannot be batched")
+)
+
+// DiscoverParams is the params of rpc.discover
+type DiscoverParams struct {
+	Method string `json:"method" doc:"Only describe this method"`
+}
+
+// SubscribeParams is the params of subscribe
+type SubscribeParams struct {
+	Topics []string `json:"topics" doc:"Topic filters like \"gamification\", empty for all topics"`
+}
+
+// UserParams is the params of methods about a user's gamification status
+type UserParams struct {
+	UserID string `json:"user_id" doc:"User ID, default the local user"`
+}
+
+// registerMethods registers the methods served by the server itself
+func (s *Server) registerMethods() {
+	registry.Register(s.methods, MethodPing, "Check that the daemon is running",
+		func(registry.None) (string, error) {
+			return "pong", nil
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodGetStatus, "Show whether the daemon runs and tracking is paused",
+		func(registry.None) (Status, error) {
+			return s.status(), nil
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodShutdown, "Stop the daemon",
+		func(registry.None) (string, error) {
+			s.mu.RLock()
+			supported := s.onShutdown != nil
+			s.mu.RUnlock()
+			if !supported {
+				return "", errShutdownUnsupported
+			}
+			return "shutting down", nil
+		})
+	// Subscriptions are streamed by the connection; the method is registered
+	// to be described and to reject batched subscriptions
+	registry.Register(s.methods, MethodSubscribe, "Stream events as \"event\" notifications",
+		func(SubscribeParams) (Subscription, error) {
+			return Subscription{}, errSubscribeBatched
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodGetGamificationStatus, "Show the level and attributes of a user",
+		func(p UserParams) (*GamificationStatus, error) {
+			return s.gamificationStatus(p.UserID)
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodGetGamificationDetails, "Show the status, modifiers and today's apps of a user",
+		func(p UserParams) (*GamificationDetails, error) {
+			return s.gamificationDetails(p.UserID)
+		}, registry.ReadOnly)
+	registry.Register(s.methods, MethodDiscover, "Describe the methods served by the daemon",
+		func(p DiscoverParams) ([]registry.Method, error) {
+			if p.Method == "" {
+				return s.methods.Methods(), nil
+			}
+			method, ok := s.methods.Lookup(p.Method)
+			if !ok {
+				return nil, &registry.ParamError{Param: "method", Err: fmt.Errorf("%w: %s", registry.ErrUnknownMethod, p.Method)}
+			}
+			return []registry.Method{method}, nil
+		}, registry.ReadOnly)
+}
+
+// status reports the state of the daemon
+func (s *Server) status() Status {
+	status := Status{
+		Running:   true,
+		StartedAt: s.startedAt,
+		Version:   "1.0.0",
+	}
+	if pause, err := s.services.Pause.State(); err == nil {
+		status.Paused = pause.Paused
+		status.PausedSince = pause.Since
+		status.PausedUntil = pause.Until
+	}
+	return status
+}
+
+// gamificationStatus reports the effective status of a user
+func (s *Server) gamificationStatus(userID string) (*GamificationStatus, error) {
+	if userID == "" {
+		userID = service.DefaultUserID
+	}
+
+	status, err := s.services.Gamification.GetEffectiveStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate next level experience requirement
+	config := s.services.Gamification.GetConfig()
+	nextLevelExp := config.ExpForLevel(status.Level + 1)
+
+	return &GamificationStatus{
+		UserID:        status.UserID,
+		Level:         status.Level,
+		Experience:    status.Experience,
+		TotalExp:      status.TotalExp,
+		NextLevelExp:  nextLevelExp,
+		Focus:         status.Focus,
+		Productivity:  status.Productivity,
+		Creativity:    status.Creativity,
+		Stamina:       status.Stamina,
+		Knowledge:     status.Knowledge,
+		Collaboration: status.Collaboration,
+		UpdatedAt:     status.UpdatedAt,
+	}, nil
+}
+
+// gamificationDetails reports the status of a user with their modifiers and
+// today's app usage
+func (s *Server) gamificationDetails(userID string) (*GamificationDetails, error) {
+	if userID == "" {
+		userID = service.DefaultUserID
+	}
+
+	status, err := s.gamificationStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	modifiers, err := s.services.Gamification.GetModifiers(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert modifiers to RPC format
+	rpcModifiers := make([]AttributeModifier, len(modifiers))
+	for i, mod := range modifiers {
+		rpcModifiers[i] = AttributeModifier{
+			Attribute: mod.Attribute,
+			Value:     mod.Value,
+			Reason:    mod.Reason,
+			ExpiresAt: mod.ExpiresAt,
+		}
+	}
+
+	// Get today's app usage
+	today := time.Now()
+	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	appUsage, _ := s.services.Activity.GetAppUsageSummary(startOfDay, today)
+
+	return &GamificationDetails{
+		Status:     status,
+		Modifiers:  rpcModifiers,
+		RecentApps: appUsage,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"shien/internal/events"
+)
+
+// connect serves one end of a pipe with an idle timeout of 50ms and returns
+// the other
+func connect(t *testing.T) (*Server, net.Conn, *json.Decoder) {
+	t.Helper()
+
+	server := newTestServer(t)
+	server.idleTimeout = 50 * time.Millisecond
+
+	client, conn := net.Pipe()
+	go server.handleConnection(conn)
+	t.Cleanup(func() { client.Close() })
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	return server, client, json.NewDecoder(client)
+}
+
+// call sends a JSON-RPC request and reads the reply
+func call(t *testing.T, conn net.Conn, decoder *json.Decoder, method string) {
+	t.Helper()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"` + method + `"}` + "\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatalf("%s: write error = %v", method, err)
+	}
+	var reply jsonrpcResponse
+	if err := decoder.Decode(&reply); err != nil {
+		t.Fatalf("%s: read error = %v", method, err)
+	}
+	if reply.Error != nil {
+		t.Fatalf("%s: error = %+v", method, reply.Error)
+	}
+}
+
+func TestIdleConnectionIsClosed(t *testing.T) {
+	_, conn, decoder := connect(t)
+
+	// Every request gives the connection another full timeout
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		call(t, conn, decoder, MethodPing)
+	}
+
+	// The daemon closes the connection, rather than the read timing out
+	var reply json.RawMessage
+	if err := decoder.Decode(&reply); !errors.Is(err, io.EOF) {
+		t.Fatalf("read error = %v, want EOF", err)
+	}
+}
+
+func TestSubscriptionOutlivesIdleTimeout(t *testing.T) {
+	server, conn, decoder := connect(t)
+	call(t, conn, decoder, MethodSubscribe)
+
+	// Subscribers send nothing more, yet still get the events
+	time.Sleep(100 * time.Millisecond)
+	server.services.Events.Publish(events.TopicActivityRecorded, nil)
+
+	var notification jsonrpcNotification
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("read error = %v", err)
+	}
+	if notification.Method != NotificationEvent {
+		t.Errorf("method = %q, want %q", notification.Method, NotificationEvent)
+	}
+}
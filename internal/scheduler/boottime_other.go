@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package scheduler
+
+import "time"
+
+// sinceBoot reports that no boot clock is available on this platform
+func sinceBoot() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,12 @@
+package scheduler
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// sinceBoot reads the tick count, which includes time spent asleep
+func sinceBoot() (time.Duration, bool) {
+	return windows.DurationSinceBoot(), true
+}
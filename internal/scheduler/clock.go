@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so the scheduler can be driven deterministically
+type Clock interface {
+	// Now returns the current wall-clock time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time
+	After(d time.Duration) <-chan time.Time
+	// SinceBoot returns the time since boot, which keeps counting while the
+	// machine is suspended and ignores wall-clock steps. ok is false when
+	// the platform has no such clock.
+	SinceBoot() (d time.Duration, ok bool)
+}
+
+// realClock uses the system clock
+type realClock struct{}
+
+// NewRealClock returns a clock backed by the system clock
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+// Now returns the wall-clock time without its monotonic reading, so that
+// comparisons see suspend gaps and clock steps
+func (realClock) Now() time.Time {
+	return time.Now().Round(0)
+}
+
+// After waits for the duration to elapse
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SinceBoot reads the platform's boot clock
+func (realClock) SinceBoot() (time.Duration, bool) {
+	return sinceBoot()
+}
+
+// FakeClock is a manually advanced clock for tests.
+// Like the runtime's timers, After waits on elapsed (monotonic) time, so
+// Set and Suspend change what Now reports without firing pending waiters.
+type FakeClock struct {
+	now     time.Time
+	elapsed time.Duration // Time waiters see; stops while suspended
+	boot    time.Duration // Time since boot; counts while suspended
+	waiters []fakeWaiter
+	mu      sync.Mutex
+}
+
+type fakeWaiter struct {
+	deadline time.Duration
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a fake clock set to the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that fires once d more time has elapsed
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeWaiter{deadline: c.elapsed + d, ch: ch})
+	return ch
+}
+
+// Advance lets time pass, firing any waiters that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.elapsed += d
+	c.boot += d
+
+	sort.Slice(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline < c.waiters[j].deadline
+	})
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline <= c.elapsed {
+			w.ch <- c.now
+		} else {
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+}
+
+// Set steps the wall clock to the given time, as an NTP correction or a
+// manual change would, without any time passing
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Suspend lets d pass while the machine sleeps: the wall clock and the boot
+// clock move on, but pending waiters don't fire
+func (c *FakeClock) Suspend(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.boot += d
+}
+
+// SinceBoot returns the fake time since boot
+func (c *FakeClock) SinceBoot() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.boot, true
+}
+
+// Waiters returns the number of pending After calls
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n After calls are pending
+func (c *FakeClock) BlockUntil(n int) {
+	for c.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
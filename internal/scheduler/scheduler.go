@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// defaultCheckEvery bounds how long the scheduler sleeps between clock checks.
+// Timers don't advance while the machine is suspended, so waking up regularly
+// is what lets us notice a resume promptly.
+const defaultCheckEvery = 30 * time.Second
+
+// Handler receives the scheduler's decisions
+type Handler interface {
+	// Tick is called once per grid slot; interval is the time the sample represents
+	Tick(slot time.Time, interval time.Duration)
+	// Gap is called when slots between from and to were missed because the
+	// machine was suspended
+	Gap(from, to time.Time)
+}
+
+// Scheduler fires once per interval on a wall-clock aligned grid
+// (e.g. :00, :05, :10 for a 5 minute interval). It detects suspend gaps,
+// realigns to the grid after clock steps and never fires a slot twice.
+// Suspends and forward clock steps both skip slots; they are told apart by
+// the boot clock, which only moves on while suspended.
+type Scheduler struct {
+	clock      Clock
+	interval   time.Duration
+	checkEvery time.Duration
+	handler    Handler
+	lastSlot   time.Time
+}
+
+// New creates a scheduler
+func New(clock Clock, interval time.Duration, handler Handler) *Scheduler {
+	checkEvery := defaultCheckEvery
+	if interval < checkEvery {
+		checkEvery = interval
+	}
+
+	return &Scheduler{
+		clock:      clock,
+		interval:   interval,
+		checkEvery: checkEvery,
+		handler:    handler,
+	}
+}
+
+// Interval returns the grid interval
+func (s *Scheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// NextSlot returns the first grid slot strictly after t
+func (s *Scheduler) NextSlot(t time.Time) time.Time {
+	return t.Truncate(s.interval).Add(s.interval)
+}
+
+// Run drives the handler until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	now := s.clock.Now()
+	next := s.NextSlot(now)
+	last := now
+	lastBoot, _ := s.clock.SinceBoot()
+
+	for {
+		wait := next.Sub(now)
+		if wait > s.checkEvery {
+			wait = s.checkEvery
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(wait):
+		}
+
+		now = s.clock.Now()
+		boot, known := s.clock.SinceBoot()
+		next = s.step(last, now, next, boot-lastBoot, known)
+		last, lastBoot = now, boot
+	}
+}
+
+// step handles one wake-up and returns the next slot to wait for. passed
+// is the time that really passed since the last wake-up, if known.
+func (s *Scheduler) step(last, now, next time.Time, passed time.Duration, known bool) time.Time {
+	// The clock was stepped backwards (NTP correction, manual change)
+	if now.Before(last) {
+		realigned := s.NextSlot(now)
+		if !s.lastSlot.IsZero() && !realigned.After(s.lastSlot) {
+			// Slots up to lastSlot were already recorded; firing them again
+			// would overwrite the records for those minutes
+			realigned = s.lastSlot.Add(s.interval)
+		}
+		log.Printf("Clock moved back by %s, next sample at %s", last.Sub(now).Round(time.Second), realigned.Format("15:04:05"))
+		return realigned
+	}
+
+	if now.Before(next) {
+		return next
+	}
+
+	// More than a whole interval passed after the slot was due: the machine
+	// was suspended or the clock jumped forward
+	if now.Sub(next) >= s.interval {
+		resumed := now.Truncate(s.interval)
+		switch {
+		case !known:
+			// Without a boot clock, a clock step can't be told from a
+			// suspend; recording nothing is better than a false suspend
+			log.Printf("Missed samples from %s to %s (suspend or clock change)", next.Format("15:04"), resumed.Format("15:04"))
+		case last.Add(passed).Sub(next) >= s.interval:
+			s.handler.Gap(next, resumed)
+		default:
+			log.Printf("Clock jumped forward by %s, next sample at %s", (now.Sub(last) - passed).Round(time.Second), resumed.Format("15:04:05"))
+		}
+
+		// The slot the machine woke up in is sampled like any other
+		s.handler.Tick(resumed, s.interval)
+		s.lastSlot = resumed
+		return resumed.Add(s.interval)
+	}
+
+	s.handler.Tick(next, s.interval)
+	s.lastSlot = next
+	return s.NextSlot(next)
+}
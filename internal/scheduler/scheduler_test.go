@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder logs the handler calls as "tick 10:01" and "gap 10:01-10:05"
+type recorder struct {
+	calls []string
+	mu    sync.Mutex
+}
+
+func (r *recorder) Tick(slot time.Time, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf("tick %s", slot.Format("15:04")))
+}
+
+func (r *recorder) Gap(from, to time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf("gap %s-%s", from.Format("15:04"), to.Format("15:04")))
+}
+
+func (r *recorder) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// harness runs a one-minute scheduler on a fake clock starting at 10:00:00
+type harness struct {
+	clock *FakeClock
+	rec   *recorder
+}
+
+func start(t *testing.T) *harness {
+	t.Helper()
+
+	h := &harness{
+		clock: NewFakeClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)),
+		rec:   &recorder{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		New(h.clock, time.Minute, h.rec).Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	h.clock.BlockUntil(1)
+	return h
+}
+
+// advance lets d pass in small steps, waiting for the scheduler to go back
+// to sleep after every wake-up
+func (h *harness) advance(d time.Duration) {
+	const step = 10 * time.Second
+	for ; d > 0; d -= step {
+		h.clock.Advance(step)
+		h.clock.BlockUntil(1)
+	}
+}
+
+func (h *harness) expect(t *testing.T, want ...string) {
+	t.Helper()
+	if got := h.rec.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestSchedulerTicksOnGrid(t *testing.T) {
+	h := start(t)
+
+	h.advance(30 * time.Second)
+	h.expect(t)
+
+	h.advance(2*time.Minute + 30*time.Second)
+	h.expect(t, "tick 10:01", "tick 10:02", "tick 10:03")
+}
+
+func TestSchedulerSuspend(t *testing.T) {
+	h := start(t)
+	h.advance(time.Minute)
+
+	// Asleep from 10:01 to 10:05:20; timers don't run meanwhile, so the
+	// scheduler notices at its next check
+	h.clock.Suspend(4*time.Minute + 20*time.Second)
+	h.advance(30 * time.Second)
+	h.expect(t, "tick 10:01", "gap 10:02-10:05", "tick 10:05")
+
+	h.advance(time.Minute)
+	h.expect(t, "tick 10:01", "gap 10:02-10:05", "tick 10:05", "tick 10:06")
+}
+
+func TestSchedulerClockJumpsForward(t *testing.T) {
+	h := start(t)
+	h.advance(time.Minute)
+
+	// An NTP step skips slots, but the machine was awake throughout
+	h.clock.Set(h.clock.Now().Add(time.Hour))
+	h.advance(30 * time.Second)
+	h.expect(t, "tick 10:01", "tick 11:01")
+
+	h.advance(time.Minute)
+	h.expect(t, "tick 10:01", "tick 11:01", "tick 11:02")
+}
+
+func TestSchedulerClockMovesBack(t *testing.T) {
+	h := start(t)
+	h.advance(2 * time.Minute)
+	h.expect(t, "tick 10:01", "tick 10:02")
+
+	// Back to 10:01:10; 10:02 was already sampled and must not fire again
+	h.clock.Set(time.Date(2024, 3, 1, 10, 1, 10, 0, time.UTC))
+	h.advance(10 * time.Second)
+	h.expect(t, "tick 10:01", "tick 10:02")
+
+	h.advance(time.Minute + 40*time.Second)
+	h.expect(t, "tick 10:01", "tick 10:02", "tick 10:03")
+}
+
+func TestSchedulerGapWithoutBootClock(t *testing.T) {
+	rec := &recorder{}
+	s := New(NewFakeClock(time.Time{}), time.Minute, rec)
+
+	at := func(hour, min, sec int) time.Time {
+		return time.Date(2024, 3, 1, hour, min, sec, 0, time.UTC)
+	}
+
+	// Slots were skipped, but nothing tells a suspend from a clock step
+	next := s.step(at(10, 0, 50), at(10, 5, 20), at(10, 1, 0), 0, false)
+	if want := at(10, 6, 0); !next.Equal(want) {
+		t.Errorf("next = %s, want %s", next.Format("15:04:05"), want.Format("15:04:05"))
+	}
+	if got, want := rec.Calls(), []string{"tick 10:05"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
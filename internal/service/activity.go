@@ -1,46 +1,293 @@
 package service
 
 import (
+	"log"
+	"sort"
+	"sync"
 	"time"
 	
+	"shien/internal/apprules"
 	"shien/internal/database/repository"
+	"shien/internal/events"
+	"shien/internal/foreground"
+	"shien/internal/idle"
+	"shien/internal/privacy"
+	"shien/internal/projects"
 	"shien/internal/utils"
 )
 
+// AppNormalizer maps raw app names to categories
+type AppNormalizer interface {
+	Normalize(raw string) apprules.Result
+}
+
+// ProjectMatcher assigns samples to projects
+type ProjectMatcher interface {
+	MatchProject(in projects.Input) *int64
+}
+
+// ManualLogSource provides manually entered activity as activity records
+type ManualLogSource interface {
+	ActivityLogs(from, to time.Time) ([]repository.ActivityLog, error)
+}
+
 // ActivityService handles business logic for activity tracking
 type ActivityService struct {
 	repo           *repository.ActivityRepo
-	lastRecordedApp string
+	provider       foreground.Provider
+	idleSource     idle.Source
+	idleThreshold  time.Duration
+	privacy        *privacy.Policy
+	normalizer     AppNormalizer
+	projects       ProjectMatcher
+	manual         ManualLogSource
+	events         *events.Bus
+	mu             sync.RWMutex
 }
 
 // NewActivityService creates a new activity service
-func NewActivityService(repo *repository.ActivityRepo) *ActivityService {
-	return &ActivityService{repo: repo}
+func NewActivityService(repo *repository.ActivityRepo, provider foreground.Provider, idleSource idle.Source) *ActivityService {
+	if provider == nil {
+		provider = foreground.NewOSProvider()
+	}
+	if idleSource == nil {
+		idleSource = &idle.NeverIdle{}
+	}
+	return &ActivityService{repo: repo, provider: provider, idleSource: idleSource}
+}
+
+// SetEventBus sets the bus recorded samples are published on
+func (s *ActivityService) SetEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = bus
+}
+
+// record stores a sample and publishes it
+func (s *ActivityService) record(sample repository.Sample) error {
+	if err := s.repo.RecordSample(sample); err != nil {
+		return err
+	}
+	
+	state := sample.State
+	if state == "" {
+		state = repository.StateActive
+	}
+	
+	s.mu.RLock()
+	bus := s.events
+	s.mu.RUnlock()
+	bus.Emit(events.ActivityRecorded{
+		At:          sample.RecordedAt,
+		DurationSec: int(sample.Duration / time.Second),
+		State:       state,
+		AppName:     sample.AppName,
+		ProjectID:   sample.ProjectID,
+	})
+	return nil
+}
+
+// SetProvider replaces the source of the foreground window
+func (s *ActivityService) SetProvider(provider foreground.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+}
+
+// SetIdleThreshold sets the idle time after which samples are recorded as idle.
+// A zero threshold disables idle detection.
+func (s *ActivityService) SetIdleThreshold(threshold time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleThreshold = threshold
+}
+
+// SetPrivacyPolicy sets the rules deciding which windows are dropped,
+// recorded as private or redacted before anything is stored
+func (s *ActivityService) SetPrivacyPolicy(policy *privacy.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.privacy = policy
+}
+
+// applyPrivacy decides how a window is recorded
+func (s *ActivityService) applyPrivacy(window *foreground.Window) privacy.Decision {
+	s.mu.RLock()
+	policy := s.privacy
+	s.mu.RUnlock()
+	
+	if policy == nil {
+		recorded := *window
+		return privacy.Decision{Action: privacy.ActionRecord, Window: &recorded}
+	}
+	return policy.Apply(window)
+}
+
+// PrivacyPreview shows how a window would be recorded
+type PrivacyPreview struct {
+	privacy.Decision
+	Category string `json:"category,omitempty"` // Category recorded, empty when dropped
+}
+
+// PreviewPrivacy reports how a window would be handled without recording anything
+func (s *ActivityService) PreviewPrivacy(window *foreground.Window) PrivacyPreview {
+	preview := PrivacyPreview{Decision: s.applyPrivacy(window)}
+	switch {
+	case preview.Window == nil:
+	case preview.Action == privacy.ActionPrivate:
+		preview.Category = privacy.PrivateAppName
+	default:
+		preview.Category = s.normalizeAppName(preview.Window.AppName)
+	}
+	return preview
+}
+
+// SetAppNormalizer sets the rules mapping raw app names to categories.
+// Without one, the built-in mapping is used.
+func (s *ActivityService) SetAppNormalizer(normalizer AppNormalizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.normalizer = normalizer
+}
+
+// normalizeAppName maps a raw app name to its category
+func (s *ActivityService) normalizeAppName(raw string) string {
+	s.mu.RLock()
+	normalizer := s.normalizer
+	s.mu.RUnlock()
+	
+	if normalizer == nil {
+		return utils.NormalizeAppName(raw)
+	}
+	return normalizer.Normalize(raw).Category
+}
+
+// SetProjectMatcher sets the matcher assigning active samples to projects
+func (s *ActivityService) SetProjectMatcher(matcher ProjectMatcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = matcher
+}
+
+// SetManualLogSource sets the source of manual entries merged into activity logs
+func (s *ActivityService) SetManualLogSource(source ManualLogSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manual = source
+}
+
+// isIdle reports whether the user has been idle longer than the threshold
+func (s *ActivityService) isIdle() bool {
+	s.mu.RLock()
+	threshold := s.idleThreshold
+	s.mu.RUnlock()
+	
+	if threshold <= 0 {
+		return false
+	}
+	
+	idleTime, err := s.idleSource.IdleTime()
+	if err != nil {
+		// Without an idle reading, assume the user is active
+		log.Printf("Failed to read idle time from %s: %v", s.idleSource.Name(), err)
+		return false
+	}
+	
+	return idleTime >= threshold
+}
+
+// RecordActivity records current activity representing the given duration
+func (s *ActivityService) RecordActivity(duration time.Duration) error {
+	return s.repo.RecordActivity(duration)
 }
 
-// RecordActivity records current activity
-func (s *ActivityService) RecordActivity() error {
-	return s.repo.RecordActivity()
+// RecordActivityWithApp records current activity with the foreground app.
+// The app name is normalized into a category while the raw name and window
+// details are kept alongside it. duration is the time the sample represents.
+func (s *ActivityService) RecordActivityWithApp(duration time.Duration) error {
+	return s.RecordSample(time.Now(), duration)
 }
 
-// RecordActivityWithApp records current activity with the foreground app
-func (s *ActivityService) RecordActivityWithApp() error {
-	// Get the current foreground application
-	appName, err := utils.GetForegroundApp()
+// RecordSample records the sample for the period starting at the given time
+func (s *ActivityService) RecordSample(at time.Time, duration time.Duration) error {
+	sample := repository.Sample{
+		RecordedAt: at,
+		State:      repository.StateActive,
+		Duration:   duration,
+	}
+	
+	// Away-from-keyboard time is recorded as idle, without app details
+	if s.isIdle() {
+		sample.State = repository.StateIdle
+		return s.record(sample)
+	}
+	
+	// Get the current foreground window
+	s.mu.RLock()
+	provider := s.provider
+	s.mu.RUnlock()
+	window, err := provider.Foreground()
 	if err != nil {
 		// If we can't get the app name, still record the activity
-		return s.repo.RecordActivity()
+		return s.record(sample)
 	}
 	
-	// Store the last recorded app name
-	s.lastRecordedApp = appName
+	// Privacy rules run before anything reaches the repository
+	decision := s.applyPrivacy(window)
+	if decision.Window == nil {
+		return nil
+	}
+	window = decision.Window
+	
+	if decision.Action == privacy.ActionPrivate {
+		sample.AppName = privacy.PrivateAppName
+	} else {
+		sample.AppName = s.normalizeAppName(window.AppName)
+	}
+	sample.Window = repository.WindowDetails{
+		RawAppName:  window.AppName,
+		WindowTitle: window.Title,
+		Document:    window.Document,
+		URL:         window.URL,
+	}
+	
+	// Assign the sample to a project
+	s.mu.RLock()
+	matcher := s.projects
+	s.mu.RUnlock()
+	if matcher != nil {
+		sample.ProjectID = matcher.MatchProject(projects.Input{
+			At:         at,
+			AppName:    sample.AppName,
+			RawAppName: window.AppName,
+			Title:      window.Title,
+		})
+	}
+	
+	return s.record(sample)
+}
+
+// RecordSuspended records that no samples were taken between from and to
+// because the machine was asleep
+func (s *ActivityService) RecordSuspended(from, to time.Time) error {
+	if !to.After(from) {
+		return nil
+	}
 	
-	return s.repo.RecordActivityWithApp(appName)
+	return s.record(repository.Sample{
+		RecordedAt: from,
+		State:      repository.StateSuspended,
+		Duration:   to.Sub(from),
+	})
 }
 
-// GetLastRecordedApp returns the last recorded app name
-func (s *ActivityService) GetLastRecordedApp() (string, error) {
-	return s.lastRecordedApp, nil
+// RecordPaused records a sample for a period in which tracking was paused
+func (s *ActivityService) RecordPaused(at time.Time, duration time.Duration) error {
+	return s.record(repository.Sample{
+		RecordedAt: at,
+		State:      repository.StatePaused,
+		Duration:   duration,
+	})
 }
 
 // GetActivityLogs retrieves activity logs for a date range
@@ -58,18 +305,91 @@ func (s *ActivityService) GetActivityLogs(from, to time.Time) ([]repository.Acti
 		to = time.Now()
 	}
 	
-	return s.repo.GetActivityLogs(from, to)
+	logs, err := s.repo.GetActivityLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	
+	return s.mergeManualLogs(logs, from, to)
 }
 
-// GetActivitySummary gets activity summary for a date
+// mergeManualLogs adds manual entries to sampled logs. Manual entries take
+// precedence: sampled records inside an entry are dropped so time isn't
+// counted twice. The result is ordered newest first like the sampled logs.
+func (s *ActivityService) mergeManualLogs(logs []repository.ActivityLog, from, to time.Time) ([]repository.ActivityLog, error) {
+	s.mu.RLock()
+	source := s.manual
+	s.mu.RUnlock()
+	if source == nil {
+		return logs, nil
+	}
+	
+	manual, err := source.ActivityLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(manual) == 0 {
+		return logs, nil
+	}
+	
+	merged := make([]repository.ActivityLog, 0, len(logs)+len(manual))
+	for _, entry := range logs {
+		if !coveredByManual(entry, manual) {
+			merged = append(merged, entry)
+		}
+	}
+	merged = append(merged, manual...)
+	
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].RecordedAt.After(merged[j].RecordedAt.Time)
+	})
+	
+	return merged, nil
+}
+
+// coveredByManual reports whether a sampled record falls inside a manual entry
+func coveredByManual(entry repository.ActivityLog, manual []repository.ActivityLog) bool {
+	for _, m := range manual {
+		start := m.RecordedAt.Time
+		end := start.Add(m.Duration())
+		if !entry.RecordedAt.Before(start) && entry.RecordedAt.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetActivitySummary gets activity summary for a date. Manual entries are
+// included, and the sampled records they cover are not counted again.
 func (s *ActivityService) GetActivitySummary(date time.Time) (map[string]interface{}, error) {
-	return s.repo.GetActivitySummary(date)
+	from := startOfDay(date)
+	to := from.AddDate(0, 0, 1)
+	
+	logs, err := s.GetActivityLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	
+	var count int
+	var active time.Duration
+	for _, entry := range logs {
+		if entry.IsActive() && entry.RecordedAt.Before(to) {
+			count++
+			active += entry.Duration()
+		}
+	}
+	
+	return map[string]interface{}{
+		"date":           date.Format("2006-01-02"),
+		"activity_count": count,
+		"minutes_active": int(active.Minutes()),
+	}, nil
 }
 
 // GetDailyStats returns statistics for today
 func (s *ActivityService) GetDailyStats() (map[string]interface{}, error) {
 	today := time.Now()
-	logs, err := s.repo.GetActivityLogs(
+	logs, err := s.GetActivityLogs(
 		time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location()),
 		today,
 	)
@@ -77,15 +397,40 @@ func (s *ActivityService) GetDailyStats() (map[string]interface{}, error) {
 		return nil, err
 	}
 	
+	// Only active records count towards active time
+	var minutesActive float64
+	for _, entry := range logs {
+		if entry.IsActive() {
+			minutesActive += entry.Minutes()
+		}
+	}
+	
 	return map[string]interface{}{
 		"date":          today.Format("2006-01-02"),
 		"record_count":  len(logs),
-		"minutes_active": len(logs) * 5,
-		"hours_active":  float64(len(logs)*5) / 60.0,
+		"minutes_active": int(minutesActive),
+		"hours_active":  minutesActive / 60.0,
 	}, nil
 }
 
-// GetAppUsageSummary returns app usage statistics for a time range
+// GetAppUsageSummary returns the active minutes per app in a time range.
+// Manual entries count under their category, like in GetActivitySummary.
 func (s *ActivityService) GetAppUsageSummary(from, to time.Time) (map[string]int, error) {
-	return s.repo.GetAppUsageSummary(from, to)
+	logs, err := s.GetActivityLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	
+	durations := make(map[string]time.Duration)
+	for _, entry := range logs {
+		if entry.IsActive() && entry.AppName != nil && *entry.AppName != "" {
+			durations[*entry.AppName] += entry.Duration()
+		}
+	}
+	
+	usage := make(map[string]int, len(durations))
+	for app, d := range durations {
+		usage[app] = int(d.Minutes())
+	}
+	return usage, nil
 }
\ No newline at end of file
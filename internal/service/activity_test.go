@@ -0,0 +1,131 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/events"
+	"shien/internal/events/eventstest"
+	"shien/internal/foreground"
+	"shien/internal/idle"
+)
+
+func TestRecordSampleIdleState(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		idle      time.Duration
+		want      string
+	}{
+		{"below the threshold", 5 * time.Minute, 4 * time.Minute, repository.StateActive},
+		{"at the threshold", 5 * time.Minute, 5 * time.Minute, repository.StateIdle},
+		{"past the threshold", 5 * time.Minute, time.Hour, repository.StateIdle},
+		{"detection disabled", 0, time.Hour, repository.StateActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := idle.NewFakeSource(tt.idle)
+			services := NewServices(newTestRepository(t), nil, foreground.NewScriptedApps("Terminal"), source)
+			services.Activity.SetIdleThreshold(tt.threshold)
+
+			at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+			if err := services.Activity.RecordSample(at, 5*time.Minute); err != nil {
+				t.Fatalf("RecordSample() error = %v", err)
+			}
+
+			logs, err := services.Activity.GetActivityLogs(at, at.Add(time.Minute))
+			if err != nil {
+				t.Fatalf("GetActivityLogs() error = %v", err)
+			}
+			if len(logs) != 1 {
+				t.Fatalf("recorded %d samples, want 1", len(logs))
+			}
+			if logs[0].State != tt.want {
+				t.Errorf("State = %q, want %q", logs[0].State, tt.want)
+			}
+			// Idle samples keep no app details
+			if isIdle := tt.want == repository.StateIdle; isIdle != (logs[0].AppName == nil) {
+				t.Errorf("AppName = %v for a %s sample", logs[0].AppName, logs[0].State)
+			}
+		})
+	}
+}
+
+func TestRecordSamplePublishesActivity(t *testing.T) {
+	services := newTestServices(t, "Terminal")
+	rec := eventstest.NewRecorder(services.Events, events.TopicActivityRecorded)
+	defer rec.Close()
+
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	if err := services.Activity.RecordSample(at, 5*time.Minute); err != nil {
+		t.Fatalf("RecordSample() error = %v", err)
+	}
+
+	got := eventstest.Expect[events.ActivityRecorded](t, rec)
+	want := events.ActivityRecorded{
+		At:          at,
+		DurationSec: 300,
+		State:       repository.StateActive,
+		AppName:     services.Activity.normalizeAppName("Terminal"),
+	}
+	if !got.At.Equal(want.At) || got.DurationSec != want.DurationSec || got.State != want.State || got.AppName != want.AppName {
+		t.Errorf("ActivityRecorded = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordSuspendedPublishesActivity(t *testing.T) {
+	services := newTestServices(t)
+	rec := eventstest.NewRecorder(services.Events, events.TopicActivityRecorded)
+	defer rec.Close()
+
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	if err := services.Activity.RecordSuspended(from, from.Add(time.Hour)); err != nil {
+		t.Fatalf("RecordSuspended() error = %v", err)
+	}
+
+	got := eventstest.Expect[events.ActivityRecorded](t, rec)
+	if got.State != repository.StateSuspended || got.DurationSec != 3600 || got.AppName != "" {
+		t.Errorf("ActivityRecorded = %+v, want an hour suspended", got)
+	}
+}
+
+func TestSummariesCountManualEntriesOnce(t *testing.T) {
+	services := newTestServices(t, "Terminal")
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+
+	// Sampled from 10:00 to 10:10, with a meeting entered for 10:05 to 10:15
+	for min := 0; min < 10; min++ {
+		if err := services.Activity.RecordSample(at(10, min), time.Minute); err != nil {
+			t.Fatalf("RecordSample() error = %v", err)
+		}
+	}
+	if _, err := services.Manual.AddEntry(at(10, 5), at(10, 15), "meeting", ""); err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+
+	summary, err := services.Activity.GetActivitySummary(day)
+	if err != nil {
+		t.Fatalf("GetActivitySummary() error = %v", err)
+	}
+	if got := summary["minutes_active"]; got != 15 {
+		t.Errorf("minutes_active = %v, want 15", got)
+	}
+	if got := summary["activity_count"]; got != 6 {
+		t.Errorf("activity_count = %v, want 6", got)
+	}
+
+	usage, err := services.Activity.GetAppUsageSummary(at(0, 0), at(23, 59))
+	if err != nil {
+		t.Fatalf("GetAppUsageSummary() error = %v", err)
+	}
+	terminal := services.Activity.normalizeAppName("Terminal")
+	if want := map[string]int{terminal: 5, "Meeting": 10}; !reflect.DeepEqual(usage, want) {
+		t.Errorf("usage = %v, want %v", usage, want)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"shien/internal/apprules"
+	"shien/internal/database/repository"
+)
+
+// AppInfo describes a raw app name seen in activity and how it is categorized
+type AppInfo struct {
+	RawAppName      string         `json:"raw_app_name"`
+	StoredCategory  string         `json:"stored_category"`  // Category recorded with the activity
+	CurrentCategory string         `json:"current_category"` // Category under the current rules
+	Source          string         `json:"source"`           // Which rules matched: file, builtin or none
+	Rule            *apprules.Rule `json:"rule,omitempty"`
+	Minutes         int            `json:"minutes"`
+	LastSeen        time.Time      `json:"last_seen"`
+}
+
+// AppsReport lists the apps seen in a time range
+type AppsReport struct {
+	RulesFile string    `json:"rules_file"`
+	RulesErr  string    `json:"rules_error,omitempty"` // Error from loading the rules file
+	Apps      []AppInfo `json:"apps"`
+}
+
+// RecategorizeResult reports the outcome of re-applying the rules to past records
+type RecategorizeResult struct {
+	From            time.Time                   `json:"from"`
+	To              time.Time                   `json:"to"`
+	DryRun          bool                        `json:"dry_run"`
+	Changes         []repository.CategoryChange `json:"changes"`
+	RowsChanged     int64                       `json:"rows_changed"`
+	RowsSkipped     int64                       `json:"rows_skipped"` // Records without a raw app name
+	SessionsRebuilt bool                        `json:"sessions_rebuilt"`
+}
+
+// AppService handles app name normalization rules
+type AppService struct {
+	repo     *repository.ActivityRepo
+	store    *apprules.Store
+	sessions *SessionService
+}
+
+// NewAppService creates a new app service
+func NewAppService(repo *repository.ActivityRepo, store *apprules.Store, sessions *SessionService) *AppService {
+	return &AppService{repo: repo, store: store, sessions: sessions}
+}
+
+// Rules returns the rule store
+func (s *AppService) Rules() *apprules.Store {
+	return s.store
+}
+
+// Watch reloads the rules file when it changes, until ctx is canceled
+func (s *AppService) Watch(ctx context.Context) {
+	s.store.Watch(ctx)
+}
+
+// ListApps returns the raw app names seen in a time range and the rule
+// that matches each under the current rules
+func (s *AppService) ListApps(from, to time.Time) (*AppsReport, error) {
+	// Default to the last 7 days if not specified
+	from, to = queryRange(from, to, func(to time.Time) time.Time {
+		return to.AddDate(0, 0, -7)
+	})
+
+	usage, err := s.repo.GetRawAppUsage(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AppsReport{RulesFile: s.store.Path(), Apps: make([]AppInfo, 0, len(usage))}
+	if err := s.store.LoadError(); err != nil {
+		report.RulesErr = err.Error()
+	}
+
+	rules := s.store.Rules()
+	for _, app := range usage {
+		result := rules.Normalize(app.RawAppName)
+		report.Apps = append(report.Apps, AppInfo{
+			RawAppName:      app.RawAppName,
+			StoredCategory:  app.AppName,
+			CurrentCategory: result.Category,
+			Source:          result.Source,
+			Rule:            result.Rule,
+			Minutes:         app.Minutes,
+			LastSeen:        app.LastSeen.Time,
+		})
+	}
+
+	return report, nil
+}
+
+// Recategorize re-applies the current rules to the records in a time range
+// using their raw app names, then rebuilds the sessions derived from them.
+// With dryRun, it only reports what would change.
+func (s *AppService) Recategorize(from, to time.Time, dryRun bool) (*RecategorizeResult, error) {
+	if from.IsZero() {
+		return nil, invalidInputf("from is required")
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.After(to) {
+		from, to = to, from
+	}
+
+	current, err := s.repo.GetRawAppCategories(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecategorizeResult{From: from, To: to, DryRun: dryRun, Changes: []repository.CategoryChange{}}
+
+	rules := s.store.Rules()
+	for _, entry := range current {
+		category := rules.Normalize(entry.RawAppName).Category
+		if category == entry.From {
+			continue
+		}
+		entry.To = category
+		result.Changes = append(result.Changes, entry)
+		result.RowsChanged += entry.Rows
+	}
+
+	sort.Slice(result.Changes, func(i, j int) bool {
+		return result.Changes[i].Rows > result.Changes[j].Rows
+	})
+
+	if result.RowsSkipped, err = s.repo.CountRecordsWithoutRawName(from, to); err != nil {
+		return nil, err
+	}
+
+	if dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	if result.RowsChanged, err = s.repo.ApplyCategoryChanges(from, to, result.Changes); err != nil {
+		return nil, err
+	}
+
+	// Sessions are built from categories, so rebuild them for the range
+	if s.sessions != nil {
+		if err := s.sessions.Rebuild(from, to); err != nil {
+			return nil, fmt.Errorf("records were recategorized but rebuilding sessions failed: %w", err)
+		}
+		result.SessionsRebuilt = true
+	}
+
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import "fmt"
+
+// InvalidInputError is returned when a request is rejected because of its
+// input rather than because the daemon failed to carry it out
+type InvalidInputError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *InvalidInputError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *InvalidInputError) Unwrap() error {
+	return e.Err
+}
+
+// invalidInput marks err as caused by the caller's input
+func invalidInput(err error) error {
+	return &InvalidInputError{Err: err}
+}
+
+// invalidInputf formats an error caused by the caller's input
+func invalidInputf(format string, args ...interface{}) error {
+	return invalidInput(fmt.Errorf(format, args...))
+}
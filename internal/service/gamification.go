@@ -2,27 +2,77 @@ package service
 
 import (
 	"fmt"
+	"log"
 	"shien/internal/database"
+	"shien/internal/database/repository"
+	"shien/internal/events"
 	"shien/internal/models/gamification"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// DefaultUserID is the user all activity is attributed to
+const DefaultUserID = "default_user"
+
 // GamificationService handles gamification business logic
 type GamificationService struct {
-	repo   *database.Repository
-	config *gamification.StatusConfig
+	repo    *database.Repository
+	config  *gamification.StatusConfig
+	pending map[string]time.Duration // Activity time not yet converted into impact units
+	enabled bool
+	events  *events.Bus
+	mu      sync.Mutex
+	
+	// statusMu serializes the read-modify-write updates of user status, which
+	// come from the event handler and from manual entries at the same time
+	statusMu sync.Mutex
 }
 
 // NewGamificationService creates a new gamification service
 func NewGamificationService(repo *database.Repository) *GamificationService {
 	return &GamificationService{
-		repo:   repo,
-		config: gamification.DefaultStatusConfig(),
+		repo:    repo,
+		config:  gamification.DefaultStatusConfig(),
+		pending: make(map[string]time.Duration),
+		enabled: true,
+	}
+}
+
+// SetEventBus sets the bus level ups and modifiers are published on
+func (s *GamificationService) SetEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = bus
+}
+
+// publish sends an event on the bus, if one is set
+func (s *GamificationService) publish(payload events.Payload) {
+	s.mu.Lock()
+	bus := s.events
+	s.mu.Unlock()
+	bus.Emit(payload)
+}
+
+// SetEnabled turns experience and attribute gains on or off.
+// Activity processed while disabled is not credited later.
+func (s *GamificationService) SetEnabled(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled = enabled
+	if !enabled {
+		s.pending = make(map[string]time.Duration)
 	}
 }
 
+// Enabled reports whether activity earns experience and attributes
+func (s *GamificationService) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled
+}
+
 // GetConfig returns the gamification configuration
 func (s *GamificationService) GetConfig() *gamification.StatusConfig {
 	return s.config
@@ -35,6 +85,14 @@ func (s *GamificationService) GetModifiers(userID string) ([]gamification.Attrib
 
 // GetOrCreateUserStatus retrieves or creates a user's status
 func (s *GamificationService) GetOrCreateUserStatus(userID string) (*gamification.UserStatus, error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	return s.getOrCreateUserStatus(userID)
+}
+
+// getOrCreateUserStatus retrieves or creates a user's status.
+// Must be called with statusMu held.
+func (s *GamificationService) getOrCreateUserStatus(userID string) (*gamification.UserStatus, error) {
 	// Try to get existing status
 	status, err := s.repo.Gamification().GetUserStatus(userID)
 	if err != nil {
@@ -70,8 +128,22 @@ func (s *GamificationService) GetOrCreateUserStatus(userID string) (*gamificatio
 
 // ProcessActivity updates user status based on activity
 func (s *GamificationService) ProcessActivity(userID string, appName string, duration time.Duration) error {
+	if !s.Enabled() {
+		return nil
+	}
+	
+	// Impacts apply per 5 minutes of activity (5 minutes = 1x, 10 minutes = 2x, etc.).
+	// Shorter samples accumulate until they add up to a whole unit.
+	multiplier := s.takeImpactUnits(userID, appName, duration)
+	if multiplier == 0 {
+		return nil
+	}
+	
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	
 	// Get current status
-	status, err := s.GetOrCreateUserStatus(userID)
+	status, err := s.getOrCreateUserStatus(userID)
 	if err != nil {
 		return err
 	}
@@ -94,12 +166,6 @@ func (s *GamificationService) ProcessActivity(userID string, appName string, dur
 		}
 	}
 	
-	// Calculate multiplier based on duration (5 minutes = 1x, 10 minutes = 2x, etc.)
-	multiplier := int(duration.Minutes() / 5)
-	if multiplier < 1 {
-		multiplier = 1
-	}
-	
 	// Apply impacts with multiplier
 	status.Focus = gamification.ClampAttribute(status.Focus + (impact.FocusImpact * multiplier))
 	status.Productivity = gamification.ClampAttribute(status.Productivity + (impact.ProductivityImpact * multiplier))
@@ -113,13 +179,12 @@ func (s *GamificationService) ProcessActivity(userID string, appName string, dur
 	status.TotalExp += expGained
 	
 	// Calculate new level
+	previousLevel := status.Level
 	newLevel := gamification.CalculateLevel(status.TotalExp, s.config)
 	if newLevel > status.Level {
 		// Level up!
 		status.Level = newLevel
 		status.Experience = gamification.CalculateCurrentLevelExp(status.TotalExp, newLevel, s.config)
-		
-		// Could trigger level up notification here
 	} else {
 		status.Experience = gamification.CalculateCurrentLevelExp(status.TotalExp, status.Level, s.config)
 	}
@@ -130,9 +195,43 @@ func (s *GamificationService) ProcessActivity(userID string, appName string, dur
 		return fmt.Errorf("failed to update user status: %w", err)
 	}
 	
+	if status.Level > previousLevel {
+		s.publish(events.LevelUp{
+			UserID:        userID,
+			Level:         status.Level,
+			PreviousLevel: previousLevel,
+			TotalExp:      status.TotalExp,
+		})
+	}
+	
 	return nil
 }
 
+// activityRecorded credits active time in an app to the default user
+func (s *GamificationService) activityRecorded(e events.ActivityRecorded) {
+	if e.State != repository.StateActive || e.AppName == "" {
+		return
+	}
+	
+	if err := s.ProcessActivity(DefaultUserID, e.AppName, e.Duration()); err != nil {
+		log.Printf("Failed to process gamification: %v", err)
+	}
+}
+
+// takeImpactUnits adds duration to the pending time for an app and returns
+// the number of whole impact units now available
+func (s *GamificationService) takeImpactUnits(userID string, appName string, duration time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	key := userID + "\x00" + appName
+	total := s.pending[key] + duration
+	units := int(total / gamification.ImpactUnit)
+	s.pending[key] = total - time.Duration(units)*gamification.ImpactUnit
+	
+	return units
+}
+
 // ApplyAttributeModifier applies a temporary or permanent modifier
 func (s *GamificationService) ApplyAttributeModifier(userID string, attribute string, value int, reason string, duration *time.Duration) error {
 	modifier := &gamification.AttributeModifier{
@@ -153,6 +252,13 @@ func (s *GamificationService) ApplyAttributeModifier(userID string, attribute st
 		return fmt.Errorf("failed to create attribute modifier: %w", err)
 	}
 	
+	s.publish(events.ModifierApplied{
+		UserID:    userID,
+		Attribute: attribute,
+		Value:     value,
+		Reason:    reason,
+		ExpiresAt: modifier.ExpiresAt,
+	})
 	return nil
 }
 
@@ -193,7 +299,10 @@ func (s *GamificationService) GetEffectiveStatus(userID string) (*gamification.U
 
 // RestoreStamina gradually restores stamina over time
 func (s *GamificationService) RestoreStamina(userID string, restoreAmount int) error {
-	status, err := s.GetOrCreateUserStatus(userID)
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	
+	status, err := s.getOrCreateUserStatus(userID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"shien/internal/events"
+	"shien/internal/events/eventstest"
+)
+
+func TestRecordedActivityLevelsUp(t *testing.T) {
+	services := newTestServices(t, "Terminal")
+	rec := eventstest.NewRecorder(services.Events, events.TopicLevelUp)
+	defer rec.Close()
+
+	// A long sample is credited through the activity.recorded event
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	if err := services.Activity.RecordSample(at, 8*time.Hour); err != nil {
+		t.Fatalf("RecordSample() error = %v", err)
+	}
+
+	levelUp := eventstest.Expect[events.LevelUp](t, rec)
+	if levelUp.UserID != DefaultUserID || levelUp.PreviousLevel != 1 || levelUp.Level <= 1 {
+		t.Errorf("LevelUp = %+v, want the default user past level 1", levelUp)
+	}
+}
+
+func TestDisabledGamificationDoesNotLevelUp(t *testing.T) {
+	services := newTestServices(t, "Terminal")
+	services.Gamification.SetEnabled(false)
+	rec := eventstest.NewRecorder(services.Events, events.TopicLevelUp)
+	defer rec.Close()
+
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	if err := services.Activity.RecordSample(at, 8*time.Hour); err != nil {
+		t.Fatalf("RecordSample() error = %v", err)
+	}
+
+	eventstest.ExpectNone(t, rec, events.TopicLevelUp, 100*time.Millisecond)
+}
+
+func TestApplyAttributeModifierPublishes(t *testing.T) {
+	services := newTestServices(t)
+	rec := eventstest.NewRecorder(services.Events, events.TopicModifierApplied)
+	defer rec.Close()
+
+	// Modifiers belong to an existing user
+	if _, err := services.Gamification.GetOrCreateUserStatus(DefaultUserID); err != nil {
+		t.Fatalf("GetOrCreateUserStatus() error = %v", err)
+	}
+
+	duration := time.Hour
+	if err := services.Gamification.ApplyAttributeModifier(DefaultUserID, "focus", 10, "Deep work", &duration); err != nil {
+		t.Fatalf("ApplyAttributeModifier() error = %v", err)
+	}
+
+	modifier := eventstest.Expect[events.ModifierApplied](t, rec)
+	if modifier.Attribute != "focus" || modifier.Value != 10 || modifier.Reason != "Deep work" || modifier.ExpiresAt == nil {
+		t.Errorf("ModifierApplied = %+v, want a focus +10 modifier that expires", modifier)
+	}
+}
+
+func TestConcurrentActivityIsCreditedOnce(t *testing.T) {
+	gamification := NewGamificationService(newTestRepository(t))
+
+	// Sampled activity and manual entries are processed at the same time;
+	// every unit must be credited exactly once
+	const updates = 20
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := gamification.ProcessActivity(DefaultUserID, "Unlisted App", 5*time.Minute); err != nil {
+				t.Errorf("ProcessActivity() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	status, err := gamification.GetOrCreateUserStatus(DefaultUserID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserStatus() error = %v", err)
+	}
+	// Apps without a predefined impact gain 3 experience per unit
+	if want := updates * 3; status.TotalExp != want {
+		t.Errorf("TotalExp = %d, want %d", status.TotalExp, want)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/models/gamification"
+	"shien/internal/utils"
+)
+
+// MaxManualEntryDuration is the longest manual entry accepted
+const MaxManualEntryDuration = 24 * time.Hour
+
+// ManualEntryService handles activity entered by the user for work away
+// from the computer
+type ManualEntryService struct {
+	repo         *repository.ManualEntryRepo
+	gamification *GamificationService
+}
+
+// NewManualEntryService creates a new manual entry service
+func NewManualEntryService(repo *repository.ManualEntryRepo, gamification *GamificationService) *ManualEntryService {
+	return &ManualEntryService{repo: repo, gamification: gamification}
+}
+
+// AddEntry records a manual entry and applies its gamification impact
+func (s *ManualEntryService) AddEntry(start, end time.Time, category, note string) (*repository.ManualEntry, error) {
+	category = canonicalCategory(category)
+	if category == "" {
+		return nil, invalidInputf("category is required")
+	}
+	if !start.Before(end) {
+		return nil, invalidInputf("end must be after start")
+	}
+	if end.Sub(start) > MaxManualEntryDuration {
+		return nil, invalidInputf("entry is longer than %s", MaxManualEntryDuration)
+	}
+	if start.After(time.Now()) {
+		return nil, invalidInputf("entry starts in the future")
+	}
+
+	entry := &repository.ManualEntry{
+		StartedAt: utils.NewUTCTime(start),
+		EndedAt:   utils.NewUTCTime(end),
+		Category:  category,
+		Note:      strings.TrimSpace(note),
+	}
+	if err := s.repo.AddEntry(entry); err != nil {
+		return nil, err
+	}
+
+	// The entry is stored even if the status update fails
+	if s.gamification != nil {
+		if err := s.gamification.ProcessActivity(DefaultUserID, category, entry.Duration()); err != nil {
+			log.Printf("Failed to process gamification for manual entry: %v", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// GetEntries returns the manual entries overlapping a time range
+func (s *ManualEntryService) GetEntries(from, to time.Time) ([]repository.ManualEntry, error) {
+	// Default to today if not specified
+	from, to = queryRange(from, to, startOfDay)
+
+	return s.repo.GetEntries(from, to)
+}
+
+// ActivityLogs returns the manual entries in a time range as activity records,
+// split at hour boundaries so they fit hourly and daily reports
+func (s *ManualEntryService) ActivityLogs(from, to time.Time) ([]repository.ActivityLog, error) {
+	entries, err := s.repo.GetEntries(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []repository.ActivityLog
+	for _, entry := range entries {
+		start, end := entry.StartedAt.Time, entry.EndedAt.Time
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+
+		for start.Before(end) {
+			pieceEnd := start.Truncate(time.Hour).Add(time.Hour)
+			if pieceEnd.After(end) {
+				pieceEnd = end
+			}
+
+			category := entry.Category
+			record := repository.ActivityLog{
+				ID:          entry.ID,
+				RecordedAt:  utils.NewUTCTime(start),
+				State:       repository.StateActive,
+				DurationSec: int(pieceEnd.Sub(start).Seconds()),
+				AppName:     &category,
+				Source:      repository.SourceManual,
+			}
+			if entry.Note != "" {
+				note := entry.Note
+				record.WindowTitle = &note
+			}
+			logs = append(logs, record)
+
+			start = pieceEnd
+		}
+	}
+
+	return logs, nil
+}
+
+// canonicalCategory matches a category case-insensitively against the
+// categories with a gamification impact, e.g. "meeting" becomes "Meeting"
+func canonicalCategory(category string) string {
+	category = strings.TrimSpace(category)
+	for name := range gamification.PredefinedActivityImpacts() {
+		if strings.EqualFold(name, category) {
+			return name
+		}
+	}
+	return category
+}
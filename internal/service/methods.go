@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"shien/internal/config"
+	"shien/internal/database/repository"
+	"shien/internal/foreground"
+	"shien/internal/rpc/method"
+	"shien/internal/rpc/registry"
+)
+
+// RegisterMethods registers the methods of every service with an RPC
+// registry
+func (s *Services) RegisterMethods(r *registry.Registry) {
+	s.Pause.registerMethods(r)
+	s.Activity.registerMethods(r)
+	s.Sessions.registerMethods(r)
+	s.Manual.registerMethods(r)
+	s.Apps.registerMethods(r)
+	s.Projects.registerMethods(r)
+	s.Config.registerMethods(r)
+}
+
+// timeRange is the params of methods reading a time range. A zero time
+// leaves that end of the range open where the method allows it.
+type timeRange struct {
+	From time.Time `json:"from" doc:"Start of the range"`
+	To   time.Time `json:"to" doc:"End of the range"`
+}
+
+// requiredRange is the params of methods changing every record in a range
+type requiredRange struct {
+	From time.Time `json:"from" rpc:"required" doc:"Start of the range"`
+	To   time.Time `json:"to" rpc:"required" doc:"End of the range"`
+}
+
+// RangeUpdate is the result of methods changing the records in a range
+type RangeUpdate struct {
+	Updated int64 `json:"updated"` // Number of records changed
+}
+
+// ConfigUpdate is the result of update_config
+type ConfigUpdate struct {
+	Config     *config.Config `json:"config"`               // Configuration after the update
+	Changed    []string       `json:"changed,omitempty"`    // Keys whose effective value changed
+	Overridden []string       `json:"overridden,omitempty"` // Updated keys still overridden by env or flags
+}
+
+// pauseParams is the params of pause
+type pauseParams struct {
+	Duration registry.Duration `json:"duration" doc:"How long to pause, at least 1m, e.g. \"30m\"; empty until resumed"`
+}
+
+func (s *PauseService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.Pause, "Pause tracking for a duration or until resumed",
+		func(p pauseParams) (*PauseState, error) {
+			return s.Pause(p.Duration.Duration())
+		})
+	registry.Register(r, method.Resume, "Resume paused tracking",
+		func(registry.None) (*PauseState, error) {
+			return s.Resume()
+		})
+}
+
+func (s *ActivityService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.GetActivityLogs, "List activity records in a time range",
+		func(p timeRange) ([]repository.ActivityLog, error) {
+			return s.GetActivityLogs(p.From, p.To)
+		}, registry.ReadOnly)
+	registry.Register(r, method.TestPrivacy, "Show how privacy rules treat a window",
+		func(p privacyParams) (PrivacyPreview, error) {
+			window := foreground.Window{AppName: p.App, Title: p.Title, URL: p.URL}
+			return s.PreviewPrivacy(&window), nil
+		}, registry.ReadOnly)
+}
+
+// privacyParams is the params of test_privacy
+type privacyParams struct {
+	App   string `json:"app" rpc:"required" doc:"App name"`
+	Title string `json:"title" doc:"Window title"`
+	URL   string `json:"url" doc:"Browser URL"`
+}
+
+func (s *SessionService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.GetSessions, "List work sessions in a time range",
+		func(p timeRange) ([]repository.Session, error) {
+			return s.GetSessions(p.From, p.To)
+		}, registry.ReadOnly)
+}
+
+// manualEntryParams is the params of add_manual_entry
+type manualEntryParams struct {
+	Start    time.Time `json:"start" rpc:"required" doc:"Start of the entry"`
+	End      time.Time `json:"end" rpc:"required" doc:"End of the entry"`
+	Category string    `json:"category" doc:"Category of the time"`
+	Note     string    `json:"note" doc:"What the time was spent on"`
+}
+
+func (s *ManualEntryService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.AddManualEntry, "Log time spent away from the computer",
+		func(p manualEntryParams) (*repository.ManualEntry, error) {
+			return s.AddEntry(p.Start, p.End, p.Category, p.Note)
+		})
+	registry.Register(r, method.GetManualEntries, "List manual entries in a time range",
+		func(p timeRange) ([]repository.ManualEntry, error) {
+			return s.GetEntries(p.From, p.To)
+		}, registry.ReadOnly)
+}
+
+// recategorizeParams is the params of recategorize_apps
+type recategorizeParams struct {
+	From   time.Time `json:"from" rpc:"required" doc:"Start of the range"`
+	To     time.Time `json:"to" doc:"End of the range, default now"`
+	DryRun bool      `json:"dry_run" doc:"Only report what would change"`
+}
+
+func (s *AppService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.GetApps, "List apps with their category and usage in a time range",
+		func(p timeRange) (*AppsReport, error) {
+			return s.ListApps(p.From, p.To)
+		}, registry.ReadOnly)
+	registry.Register(r, method.RecategorizeApps, "Re-apply the app rules to recorded activity",
+		func(p recategorizeParams) (*RecategorizeResult, error) {
+			return s.Recategorize(p.From, p.To, p.DryRun)
+		})
+}
+
+// createProjectParams is the params of create_project
+type createProjectParams struct {
+	Name        string `json:"name" rpc:"required" doc:"Project name"`
+	Description string `json:"description" doc:"Project description"`
+}
+
+// projectRuleParams is the params of add_project_rule
+type projectRuleParams struct {
+	Project  string `json:"project" rpc:"required" doc:"Project name"`
+	Kind     string `json:"kind" rpc:"required" doc:"What the pattern matches: app, title or time"`
+	Pattern  string `json:"pattern" rpc:"required" doc:"Pattern to match"`
+	Priority int    `json:"priority" doc:"Rules with higher priority are tried first"`
+}
+
+// ruleIDParams is the params of remove_project_rule
+type ruleIDParams struct {
+	ID int64 `json:"id" rpc:"required" doc:"Rule ID"`
+}
+
+// assignParams is the params of assign_project
+type assignParams struct {
+	requiredRange
+	Project string `json:"project" doc:"Project name, empty to clear the assignment"`
+}
+
+// tagParams is the params of tag_activity
+type tagParams struct {
+	requiredRange
+	Tag string `json:"tag" rpc:"required" doc:"Tag to attach"`
+}
+
+func (s *ProjectService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.CreateProject, "Create a project",
+		func(p createProjectParams) (*repository.Project, error) {
+			return s.CreateProject(p.Name, p.Description)
+		})
+	registry.Register(r, method.GetProjects, "List projects",
+		func(registry.None) ([]repository.Project, error) {
+			return s.GetProjects()
+		}, registry.ReadOnly)
+	registry.Register(r, method.AddProjectRule, "Add a rule assigning matching activity to a project",
+		func(p projectRuleParams) (*repository.ProjectRule, error) {
+			return s.AddRule(p.Project, p.Kind, p.Pattern, p.Priority)
+		})
+	registry.Register(r, method.RemoveProjectRule, "Remove a project rule",
+		func(p ruleIDParams) (registry.None, error) {
+			return registry.None{}, s.RemoveRule(p.ID)
+		})
+	registry.Register(r, method.GetProjectRules, "List project rules",
+		func(registry.None) ([]repository.ProjectRule, error) {
+			return s.GetRules()
+		}, registry.ReadOnly)
+	registry.Register(r, method.AssignProject, "Assign the activity in a time range to a project",
+		func(p assignParams) (RangeUpdate, error) {
+			updated, err := s.AssignRange(p.From, p.To, p.Project)
+			return RangeUpdate{Updated: updated}, err
+		})
+	registry.Register(r, method.TagActivity, "Tag the activity in a time range",
+		func(p tagParams) (RangeUpdate, error) {
+			updated, err := s.TagRange(p.From, p.To, p.Tag)
+			return RangeUpdate{Updated: updated}, err
+		})
+	registry.Register(r, method.GetProjectReport, "Report the time per project and tag in a time range",
+		func(p timeRange) (*ProjectReport, error) {
+			return s.GetReport(p.From, p.To)
+		}, registry.ReadOnly)
+}
+
+// updateConfigParams is the params of update_config: settings by key,
+// either under "updates" or as the params themselves
+type updateConfigParams struct {
+	Updates map[string]interface{} `json:"updates" doc:"Settings to change by key; keys may also be given as params"`
+}
+
+// UnmarshalJSON reads the settings from "updates" or from the params
+func (p *updateConfigParams) UnmarshalJSON(data []byte) error {
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+
+	if updates, ok := params["updates"].(map[string]interface{}); ok {
+		p.Updates = updates
+	} else {
+		p.Updates = params
+	}
+	return nil
+}
+
+func (s *ConfigService) registerMethods(r *registry.Registry) {
+	registry.Register(r, method.GetConfig, "Show the effective configuration",
+		func(registry.None) (*config.Config, error) {
+			return s.GetConfig(), nil
+		}, registry.ReadOnly)
+	registry.Register(r, method.DescribeConfig, "List every setting with its value and source",
+		func(registry.None) (*ConfigDescription, error) {
+			return s.DescribeConfig(), nil
+		}, registry.ReadOnly)
+	registry.Register(r, method.UpdateConfig, "Change settings in config.json",
+		func(p updateConfigParams) (*ConfigUpdate, error) {
+			changed, overridden, err := s.UpdateConfig(p.Updates)
+			if err != nil {
+				return nil, err
+			}
+			return &ConfigUpdate{Config: s.GetConfig(), Changed: changed, Overridden: overridden}, nil
+		})
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/events"
+)
+
+// MinPauseDuration is the shortest timed pause. Resume times are rounded up
+// to whole minutes, which would make a shorter pause several times too long.
+const MinPauseDuration = time.Minute
+
+// PauseState describes whether tracking is paused
+type PauseState struct {
+	Paused bool       `json:"paused"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"` // nil when paused until resumed
+}
+
+// PauseService handles pausing and resuming tracking. The pause is stored in
+// the database so it survives daemon restarts.
+type PauseService struct {
+	repo   *repository.PauseRepo
+	events *events.Bus
+	mu     sync.Mutex
+}
+
+// NewPauseService creates a new pause service
+func NewPauseService(repo *repository.PauseRepo) *PauseService {
+	return &PauseService{repo: repo}
+}
+
+// SetEventBus sets the bus pause state changes are published on
+func (s *PauseService) SetEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = bus
+}
+
+// publish announces a new pause state. Must be called with the lock held.
+func (s *PauseService) publish(state *PauseState) {
+	s.events.Emit(events.PauseChanged{
+		Paused: state.Paused,
+		Since:  state.Since,
+		Until:  state.Until,
+	})
+}
+
+// Pause stops tracking for the duration, or until resumed if it is zero.
+// Pausing while already paused changes when tracking resumes.
+func (s *PauseService) Pause(duration time.Duration) (*PauseState, error) {
+	if duration < 0 || (duration > 0 && duration < MinPauseDuration) {
+		return nil, invalidInputf("pause duration %s is shorter than a minute", duration)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var resumeAt time.Time
+	if duration > 0 {
+		resumeAt = now.Add(duration)
+	}
+
+	current, err := s.openPause(now)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		if err := s.repo.SetResumeAt(current.ID, resumeAt); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.repo.StartPause(now, resumeAt); err != nil {
+		return nil, err
+	}
+
+	state, err := s.state(now)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(state)
+	return state, nil
+}
+
+// Resume restarts tracking
+func (s *PauseService) Resume() (*PauseState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	current, err := s.openPause(now)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &PauseState{Paused: false}
+	if current != nil {
+		if err := s.repo.EndPause(current.ID, now); err != nil {
+			return nil, err
+		}
+		s.publish(state)
+	}
+
+	return state, nil
+}
+
+// Toggle pauses tracking until resumed, or resumes it if paused
+func (s *PauseService) Toggle() (*PauseState, error) {
+	state, err := s.State()
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Paused {
+		return s.Resume()
+	}
+	return s.Pause(0)
+}
+
+// State returns whether tracking is paused
+func (s *PauseService) State() (*PauseState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(time.Now())
+}
+
+// IsPaused reports whether tracking is paused at the given time.
+// Errors are logged and treated as not paused.
+func (s *PauseService) IsPaused(at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pause, err := s.openPause(at)
+	if err != nil {
+		log.Printf("Failed to read pause state: %v", err)
+		return false
+	}
+	return pause != nil
+}
+
+// GetPauses returns the pauses overlapping a time range
+func (s *PauseService) GetPauses(from, to time.Time) ([]repository.Pause, error) {
+	return s.repo.GetPauses(from, to)
+}
+
+// state builds the pause state at the given time
+func (s *PauseService) state(now time.Time) (*PauseState, error) {
+	pause, err := s.openPause(now)
+	if err != nil {
+		return nil, err
+	}
+	if pause == nil {
+		return &PauseState{Paused: false}, nil
+	}
+
+	since := pause.StartedAt.Time
+	state := &PauseState{Paused: true, Since: &since}
+	if pause.ResumeAt != nil {
+		until := pause.ResumeAt.Time
+		state.Until = &until
+	}
+	return state, nil
+}
+
+// openPause returns the pause in effect at the given time, closing it first
+// if its resume time has passed (e.g. while the daemon wasn't running)
+func (s *PauseService) openPause(now time.Time) (*repository.Pause, error) {
+	pause, err := s.repo.GetOpenPause()
+	if err != nil || pause == nil {
+		return nil, err
+	}
+
+	if pause.ResumeAt != nil && !now.Before(pause.ResumeAt.Time) {
+		if err := s.repo.EndPause(pause.ID, pause.ResumeAt.Time); err != nil {
+			return nil, err
+		}
+		s.publish(&PauseState{Paused: false})
+		return nil, nil
+	}
+
+	return pause, nil
+}
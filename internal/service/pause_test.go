@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shien/internal/events"
+	"shien/internal/events/eventstest"
+)
+
+func TestPauseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		wantErr  bool
+	}{
+		{"until resumed", 0, false},
+		{"one minute", time.Minute, false},
+		{"half an hour", 30 * time.Minute, false},
+		{"seconds", 30 * time.Second, true},
+		{"negative", -time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pause := newTestServices(t).Pause
+
+			state, err := pause.Pause(tt.duration)
+			if tt.wantErr {
+				var invalid *InvalidInputError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("Pause(%s) error = %v, want invalid input", tt.duration, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Pause(%s) error = %v", tt.duration, err)
+			}
+			if !state.Paused {
+				t.Errorf("Pause(%s) left tracking running", tt.duration)
+			}
+			if got := state.Until != nil; got != (tt.duration > 0) {
+				t.Errorf("Pause(%s) Until = %v", tt.duration, state.Until)
+			}
+		})
+	}
+}
+
+func TestPausePublishesChanges(t *testing.T) {
+	services := newTestServices(t)
+	rec := eventstest.NewRecorder(services.Events, events.TopicPauseChanged)
+	defer rec.Close()
+
+	before := time.Now()
+	if _, err := services.Pause.Pause(30 * time.Minute); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	paused := eventstest.Expect[events.PauseChanged](t, rec)
+	if !paused.Paused || paused.Since == nil || paused.Until == nil {
+		t.Fatalf("PauseChanged = %+v, want paused with a resume time", paused)
+	}
+
+	// The stored resume time has minute precision; it may be late by less
+	// than a minute but never early
+	if remaining := paused.Until.Sub(before); remaining < 30*time.Minute || remaining >= 31*time.Minute+time.Second {
+		t.Errorf("paused for %s from the call, want 30m to 31m", remaining)
+	}
+
+	rec.Reset()
+	if _, err := services.Pause.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if resumed := eventstest.Expect[events.PauseChanged](t, rec); resumed.Paused {
+		t.Errorf("PauseChanged = %+v, want resumed", resumed)
+	}
+
+	// Resuming again changes nothing
+	rec.Reset()
+	if _, err := services.Pause.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	eventstest.ExpectNone(t, rec, events.TopicPauseChanged, 50*time.Millisecond)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/projects"
+)
+
+// ProjectReport is the time attributed to projects and tags in a range
+type ProjectReport struct {
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Projects []repository.ProjectUsage `json:"projects"`
+	Tags     map[string]int            `json:"tags,omitempty"` // Tag -> minutes
+}
+
+// ProjectService handles projects, tags and the rules assigning samples to projects
+type ProjectService struct {
+	repo   *repository.ProjectRepo
+	engine *projects.Engine // Compiled rules, nil until first use or after a change
+	mu     sync.Mutex
+}
+
+// NewProjectService creates a new project service
+func NewProjectService(repo *repository.ProjectRepo) *ProjectService {
+	return &ProjectService{repo: repo}
+}
+
+// CreateProject creates a new project
+func (s *ProjectService) CreateProject(name, description string) (*repository.Project, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalidInputf("project name is required")
+	}
+
+	existing, err := s.repo.GetProjectByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, invalidInputf("project %q already exists", name)
+	}
+
+	return s.repo.CreateProject(name, description)
+}
+
+// GetProjects returns all projects
+func (s *ProjectService) GetProjects() ([]repository.Project, error) {
+	return s.repo.GetProjects()
+}
+
+// AddRule adds a rule assigning matching samples to the named project
+func (s *ProjectService) AddRule(projectName, kind, pattern string, priority int) (*repository.ProjectRule, error) {
+	project, err := s.projectByName(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := projects.Validate(projects.Rule{Kind: kind, Pattern: pattern}); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	rule := &repository.ProjectRule{
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+		Kind:        kind,
+		Pattern:     pattern,
+		Priority:    priority,
+	}
+	if err := s.repo.AddRule(rule); err != nil {
+		return nil, err
+	}
+
+	s.invalidateRules()
+	return rule, nil
+}
+
+// RemoveRule deletes a rule
+func (s *ProjectService) RemoveRule(id int64) error {
+	removed, err := s.repo.RemoveRule(id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return invalidInputf("rule %d not found", id)
+	}
+
+	s.invalidateRules()
+	return nil
+}
+
+// GetRules returns all rules
+func (s *ProjectService) GetRules() ([]repository.ProjectRule, error) {
+	return s.repo.GetRules()
+}
+
+// AssignRange assigns every record in the range to the named project.
+// An empty project name clears the assignment.
+func (s *ProjectService) AssignRange(from, to time.Time, projectName string) (int64, error) {
+	if !from.Before(to) {
+		return 0, invalidInputf("invalid range: %s is not before %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	var projectID *int64
+	if projectName != "" {
+		project, err := s.projectByName(projectName)
+		if err != nil {
+			return 0, err
+		}
+		projectID = &project.ID
+	}
+
+	return s.repo.AssignProject(from, to, projectID)
+}
+
+// TagRange attaches a tag to every record in the range
+func (s *ProjectService) TagRange(from, to time.Time, tag string) (int64, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return 0, invalidInputf("tag is required")
+	}
+	if !from.Before(to) {
+		return 0, invalidInputf("invalid range: %s is not before %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return s.repo.TagActivity(from, to, tag)
+}
+
+// GetReport returns the time per project and tag in the range
+func (s *ProjectService) GetReport(from, to time.Time) (*ProjectReport, error) {
+	// Default to today if not specified
+	from, to = queryRange(from, to, startOfDay)
+
+	usage, err := s.repo.GetProjectReport(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.GetTagReport(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectReport{From: from, To: to, Projects: usage, Tags: tags}, nil
+}
+
+// MatchProject returns the project the rules assign to a sample, or nil
+func (s *ProjectService) MatchProject(in projects.Input) *int64 {
+	engine, err := s.rules()
+	if err != nil {
+		log.Printf("Failed to load project rules: %v", err)
+		return nil
+	}
+
+	if id, ok := engine.Match(in); ok {
+		return &id
+	}
+	return nil
+}
+
+// rules returns the compiled rules, loading them on first use
+func (s *ProjectService) rules() (*projects.Engine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.engine != nil {
+		return s.engine, nil
+	}
+
+	stored, err := s.repo.GetRules()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]projects.Rule, 0, len(stored))
+	for _, rule := range stored {
+		rules = append(rules, projects.Rule{
+			ID:        rule.ID,
+			ProjectID: rule.ProjectID,
+			Kind:      rule.Kind,
+			Pattern:   rule.Pattern,
+			Priority:  rule.Priority,
+		})
+	}
+
+	engine, err := projects.NewEngine(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	s.engine = engine
+	return engine, nil
+}
+
+// invalidateRules makes the next match reload the rules
+func (s *ProjectService) invalidateRules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.engine = nil
+}
+
+// projectByName returns the named project or an error if it doesn't exist
+func (s *ProjectService) projectByName(name string) (*repository.Project, error) {
+	project, err := s.repo.GetProjectByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, invalidInputf("project %q not found", name)
+	}
+	return project, nil
+}
@@ -1,8 +1,20 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	
+	"shien/internal/apprules"
 	"shien/internal/config"
 	"shien/internal/database"
+	"shien/internal/events"
+	"shien/internal/foreground"
+	"shien/internal/idle"
+	"shien/internal/paths"
+	"shien/internal/privacy"
 )
 
 // Services aggregates all service layers
@@ -10,15 +22,80 @@ type Services struct {
 	Activity     *ActivityService
 	Config       *ConfigService
 	Gamification *GamificationService
+	Sessions     *SessionService
+	Projects     *ProjectService
+	Manual       *ManualEntryService
+	Apps         *AppService
+	Pause        *PauseService
+	Events       *events.Bus // Events published by the services
 }
 
 // NewServices creates all services
-func NewServices(repo *database.Repository, cfg *config.Manager) *Services {
+func NewServices(repo *database.Repository, cfg *config.Manager, provider foreground.Provider, idleSource idle.Source) *Services {
+	configService := NewConfigService(cfg)
+	bus := events.NewBus()
+	
+	activity := NewActivityService(repo.Activity(), provider, idleSource)
+	activity.SetEventBus(bus)
+	
+	// App name rules fall back to the built-in mapping until the file loads
+	appRules := apprules.NewStore(paths.AppRulesFile())
+	if err := appRules.Load(); err != nil {
+		log.Printf("Failed to load app rules: %v, using built-in rules", err)
+	}
+	activity.SetAppNormalizer(appRules)
+	
+	projects := NewProjectService(repo.Projects())
+	activity.SetProjectMatcher(projects)
+	
+	gamification := NewGamificationService(repo)
+	gamification.SetEventBus(bus)
+	manual := NewManualEntryService(repo.ManualEntries(), gamification)
+	activity.SetManualLogSource(manual)
+	
+	sessions := NewSessionService(repo.Sessions(), repo.Activity())
+	
+	// Recorded samples earn experience and extend sessions
+	events.Handle(bus, gamification.activityRecorded)
+	events.Handle(bus, sessions.activityRecorded)
+	
+	// Apply the settings now and again whenever they are updated
+	applyConfig(configService.GetConfig(), activity, sessions, gamification)
+	if cfg != nil {
+		cfg.Subscribe(func(old, new *config.Config) {
+			applyConfig(new, activity, sessions, gamification)
+			bus.Emit(events.ConfigChanged{Changed: changedKeys(old, new)})
+		})
+	}
+	
+	pause := NewPauseService(repo.Pauses())
+	pause.SetEventBus(bus)
+	
 	return &Services{
-		Activity:     NewActivityService(repo.Activity()),
-		Config:       NewConfigService(cfg),
-		Gamification: NewGamificationService(repo),
+		Activity:     activity,
+		Config:       configService,
+		Gamification: gamification,
+		Sessions:     sessions,
+		Projects:     projects,
+		Manual:       manual,
+		Apps:         NewAppService(repo.Activity(), appRules, sessions),
+		Pause:        pause,
+		Events:       bus,
+	}
+}
+
+// applyConfig applies the settings the services use while running
+func applyConfig(cfg *config.Config, activity *ActivityService, sessions *SessionService, gamification *GamificationService) {
+	// Invalid privacy rules fail closed: every title is redacted until fixed
+	policy, err := privacy.NewPolicy(cfg.PrivacyRules)
+	if err != nil {
+		log.Printf("Invalid privacy rules: %v, redacting all window details", err)
+		policy = privacy.RedactAll()
 	}
+	activity.SetPrivacyPolicy(policy)
+	activity.SetIdleThreshold(cfg.IdleThreshold.Duration())
+	sessions.SetGapTolerance(cfg.SessionGapTolerance.Duration())
+	gamification.SetEnabled(cfg.GamificationEnabled)
 }
 
 // ConfigService handles configuration logic
@@ -39,8 +116,81 @@ func (s *ConfigService) GetConfig() *config.Config {
 	return s.manager.Get()
 }
 
-// UpdateConfig updates configuration
-func (s *ConfigService) UpdateConfig(updates map[string]interface{}) error {
-	// Future implementation
-	return nil
+// ConfigDescription lists every setting with its effective value
+type ConfigDescription struct {
+	File      string           `json:"file"`
+	LoadError string           `json:"load_error,omitempty"` // Why the file is not in effect
+	Warnings  []string         `json:"warnings,omitempty"`   // Ignored environment and flag settings
+	Settings  []config.Setting `json:"settings"`
+}
+
+// DescribeConfig returns the schema with the effective value of every setting
+func (s *ConfigService) DescribeConfig() *ConfigDescription {
+	manager := s.manager
+	if manager == nil {
+		manager = &config.Manager{}
+	}
+	
+	description := &ConfigDescription{
+		File:     manager.ConfigPath(),
+		Settings: manager.Describe(),
+		Warnings: manager.Warnings(),
+	}
+	if err := manager.LoadError(); err != nil {
+		description.LoadError = err.Error()
+	}
+	return description
+}
+
+// UpdateConfig applies a partial update keyed by setting name to config.json.
+// Every value is type-checked and the resulting configuration validated
+// before anything is saved, so either all updates are applied or none.
+// It returns the keys whose effective value changed and the updated keys
+// that stay overridden by an environment variable or daemon flag.
+func (s *ConfigService) UpdateConfig(updates map[string]interface{}) (changed, overridden []string, err error) {
+	if s.manager == nil {
+		return nil, nil, fmt.Errorf("configuration is not available")
+	}
+	if len(updates) == 0 {
+		return nil, nil, invalidInputf("no settings to update")
+	}
+	
+	// Type errors and invalid values are reported before the file is touched
+	before, after, err := s.manager.Update(func(cfg *config.Config) error {
+		for key, value := range updates {
+			if err := cfg.Set(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var invalid *config.InvalidUpdateError
+	if errors.As(err, &invalid) {
+		return nil, nil, invalidInput(invalid.Err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	for key := range updates {
+		if source := s.manager.Source(key); source == config.SourceEnv || source == config.SourceFlag {
+			overridden = append(overridden, key)
+		}
+	}
+	sort.Strings(overridden)
+	
+	return changedKeys(before, after), overridden, nil
+}
+
+// changedKeys returns the keys whose value differs between two configurations
+func changedKeys(old, new *config.Config) []string {
+	var changed []string
+	for _, key := range config.Keys() {
+		before, _ := old.Value(key)
+		after, _ := new.Value(key)
+		if !reflect.DeepEqual(before, after) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"shien/internal/database"
+	"shien/internal/foreground"
+	"shien/internal/idle"
+	"shien/internal/paths"
+)
+
+// newTestRepository opens a fresh database in a temporary data directory
+func newTestRepository(t *testing.T) *database.Repository {
+	t.Helper()
+
+	if err := paths.SetDataDir(t.TempDir()); err != nil {
+		t.Fatalf("SetDataDir() error = %v", err)
+	}
+	db, err := database.New()
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return database.NewRepository(db)
+}
+
+// newTestServices wires the services to a fresh database. Samples see the
+// given foreground apps in turn.
+func newTestServices(t *testing.T, apps ...string) *Services {
+	t.Helper()
+	return NewServices(newTestRepository(t), nil, foreground.NewScriptedApps(apps...), &idle.NeverIdle{})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"shien/internal/database/repository"
+	"shien/internal/events"
+	"shien/internal/utils"
+)
+
+// SessionService merges activity samples into continuous work sessions
+type SessionService struct {
+	repo         *repository.SessionRepo
+	activity     *repository.ActivityRepo
+	gapTolerance time.Duration
+	mu           sync.Mutex
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(repo *repository.SessionRepo, activity *repository.ActivityRepo) *SessionService {
+	return &SessionService{repo: repo, activity: activity}
+}
+
+// SetGapTolerance sets the largest gap between two samples of the same app
+// that still continues a session
+func (s *SessionService) SetGapTolerance(tolerance time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gapTolerance = tolerance
+}
+
+// activityRecorded merges active samples into the current session
+func (s *SessionService) activityRecorded(e events.ActivityRecorded) {
+	if e.State != repository.StateActive {
+		return
+	}
+
+	if err := s.AddSample(e.AppName, e.At, e.Duration()); err != nil {
+		log.Printf("Failed to update sessions: %v", err)
+	}
+}
+
+// AddSample adds an active sample of appName covering [at, at+duration).
+// It extends the latest session when it is for the same app and the gap is
+// within the tolerance, and starts a new session otherwise.
+func (s *SessionService) AddSample(appName string, at time.Time, duration time.Duration) error {
+	if appName == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	end := at.Add(duration)
+
+	latest, err := s.repo.GetLatestSession()
+	if err != nil {
+		return err
+	}
+
+	if latest != nil {
+		latestEnd := latest.EndedAt.Time
+		// Samples already covered (e.g. re-recorded after the clock moved back)
+		if !end.After(latestEnd) {
+			return nil
+		}
+
+		if s.continues(latest, appName, at) {
+			return s.repo.ExtendSession(latest.ID, end, duration)
+		}
+	}
+
+	return s.repo.CreateSession(&repository.Session{
+		AppName:     appName,
+		StartedAt:   utils.NewUTCTime(at),
+		EndedAt:     utils.NewUTCTime(end),
+		SampleCount: 1,
+		DurationSec: int(duration.Seconds()),
+	})
+}
+
+// continues reports whether a sample of appName starting at the given time
+// continues the session
+func (s *SessionService) continues(session *repository.Session, appName string, at time.Time) bool {
+	return session.AppName == appName && at.Sub(session.EndedAt.Time) <= s.gapTolerance
+}
+
+// Rebuild recomputes the sessions overlapping a time range from the stored
+// activity records, e.g. after the records were recategorized
+func (s *SessionService) Rebuild(from, to time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Widen the range to whole sessions so none are cut in half. The
+	// sessions touching the widened range are replaced too, so widen until
+	// the range covers every session that will be deleted.
+	for {
+		existing, err := s.repo.GetSessions(from, to)
+		if err != nil {
+			return err
+		}
+
+		widened := false
+		for _, session := range existing {
+			if session.StartedAt.Before(from) {
+				from = session.StartedAt.Time
+				widened = true
+			}
+			if session.EndedAt.After(to) {
+				to = session.EndedAt.Time
+				widened = true
+			}
+		}
+		if !widened {
+			break
+		}
+	}
+
+	logs, err := s.activity.GetActivityLogs(from, to)
+	if err != nil {
+		return err
+	}
+
+	// Records are newest first; replay them in order
+	var sessions []repository.Session
+	for i := len(logs) - 1; i >= 0; i-- {
+		entry := logs[i]
+		if !entry.IsActive() || entry.AppName == nil || *entry.AppName == "" {
+			continue
+		}
+
+		at := entry.RecordedAt.Time
+		end := at.Add(entry.Duration())
+		if n := len(sessions); n > 0 && s.continues(&sessions[n-1], *entry.AppName, at) {
+			sessions[n-1].EndedAt = utils.NewUTCTime(end)
+			sessions[n-1].SampleCount++
+			sessions[n-1].DurationSec += int(entry.Duration().Seconds())
+			continue
+		}
+
+		sessions = append(sessions, repository.Session{
+			AppName:     *entry.AppName,
+			StartedAt:   utils.NewUTCTime(at),
+			EndedAt:     utils.NewUTCTime(end),
+			SampleCount: 1,
+			DurationSec: int(entry.Duration().Seconds()),
+		})
+	}
+
+	return s.repo.ReplaceSessions(from, to, sessions)
+}
+
+// GetSessions returns the sessions overlapping a time range
+func (s *SessionService) GetSessions(from, to time.Time) ([]repository.Session, error) {
+	// Default to today if not specified
+	from, to = queryRange(from, to, startOfDay)
+
+	return s.repo.GetSessions(from, to)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"shien/internal/database/repository"
+)
+
+func TestRebuildKeepsAdjacentSessions(t *testing.T) {
+	repo := newTestRepository(t)
+	sessions := NewSessionService(repo.Sessions(), repo.Activity())
+	sessions.SetGapTolerance(2 * time.Minute)
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	record := func(app string, start time.Time) {
+		t.Helper()
+		sample := repository.Sample{RecordedAt: start, State: repository.StateActive, Duration: 5 * time.Minute, AppName: app}
+		if err := repo.Activity().RecordSample(sample); err != nil {
+			t.Fatalf("RecordSample() error = %v", err)
+		}
+		if err := sessions.AddSample(app, start, 5*time.Minute); err != nil {
+			t.Fatalf("AddSample() error = %v", err)
+		}
+	}
+
+	// Editor 9:00-9:05, then Terminal 9:05-10:00
+	record("Editor", at(9, 0))
+	for min := 5; min < 60; min += 5 {
+		record("Terminal", at(9, min))
+	}
+
+	// The Terminal session reaches back to 9:05, where the Editor one ends
+	if err := sessions.Rebuild(at(9, 30), at(11, 0)); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+
+	got, err := sessions.GetSessions(day, at(23, 0))
+	if err != nil {
+		t.Fatalf("GetSessions() error = %v", err)
+	}
+	var summary []string
+	for _, session := range got {
+		summary = append(summary, fmt.Sprintf("%s %s-%s %d",
+			session.AppName, session.StartedAt.Local().Format("15:04"), session.EndedAt.Local().Format("15:04"), session.SampleCount))
+	}
+	want := []string{"Editor 09:00-09:05 1", "Terminal 09:05-10:00 11"}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("sessions = %q, want %q", summary, want)
+	}
+}
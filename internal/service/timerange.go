@@ -0,0 +1,24 @@
+package service
+
+import "time"
+
+// queryRange fills in the unset ends of a query range and then orders it.
+// Defaults are applied first so that a lone from or to is never swapped
+// with a default. An unset to means now; an unset from is derived from to.
+func queryRange(from, to time.Time, defaultFrom func(to time.Time) time.Time) (time.Time, time.Time) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = defaultFrom(to)
+	}
+	if from.After(to) {
+		from, to = to, from
+	}
+	return from, to
+}
+
+// startOfDay returns midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
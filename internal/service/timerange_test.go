@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryRange(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 3, day, hour, 0, 0, 0, time.Local)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"both set", at(1, 9), at(1, 17), at(1, 9), at(1, 17)},
+		{"reversed", at(1, 17), at(1, 9), at(1, 9), at(1, 17)},
+		{"only to", time.Time{}, at(1, 17), at(1, 0), at(1, 17)},
+		{"only from", at(1, 9), time.Time{}, at(1, 9), now},
+		{"neither", time.Time{}, time.Time{}, startOfDay(now), now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := queryRange(tt.from, tt.to, startOfDay)
+			if !from.Equal(tt.wantFrom) {
+				t.Errorf("from = %s, want %s", from, tt.wantFrom)
+			}
+			// An unset to is filled in with the time of the call
+			if d := to.Sub(tt.wantTo); d < 0 || d > time.Minute {
+				t.Errorf("to = %s, want %s", to, tt.wantTo)
+			}
+		})
+	}
+}
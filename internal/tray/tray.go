@@ -8,17 +8,31 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"shien/internal/events"
 	"shien/internal/notification"
 	"shien/internal/version"
 	"time"
 )
 
+// PauseController pauses and resumes tracking from the tray menu
+type PauseController interface {
+	IsPaused() bool
+	TogglePause() (paused bool, err error)
+}
+
 type Tray struct {
 	title         string
 	tooltip       string
 	notifications chan Notification
 	quit          chan struct{}
 	notifier      *notification.Manager
+	pause         PauseController
+	pauseChanged  chan struct{}          // Signals that the tracking state changed
+	subs          []*events.Subscription // Events the tray listens to
+	osNotify      bool                   // Whether notifications are also shown by the OS
+	sound         string                 // OS notification sound, empty for none
+	mu            sync.RWMutex
 }
 
 type Notification struct {
@@ -33,10 +47,52 @@ func New() *Tray {
 		tooltip:       "Supporting knowledge workers",
 		notifications: make(chan Notification, 100),
 		quit:          make(chan struct{}),
+		pauseChanged:  make(chan struct{}, 1),
 		notifier:      notification.NewManager(),
+		osNotify:      true,
 	}
 }
 
+// SetNotificationSettings sets whether notifications are shown by the OS and
+// with which sound. Notifications are kept in the tray menu either way.
+func (t *Tray) SetNotificationSettings(enabled bool, sound string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.osNotify = enabled
+	t.sound = sound
+}
+
+// SetPauseController enables the pause menu item. Call it before Start.
+func (t *Tray) SetPauseController(controller PauseController) {
+	t.pause = controller
+}
+
+// Listen shows level ups and pause changes as notifications and keeps the
+// pause menu item in step with the tracking state. Call it before Start.
+func (t *Tray) Listen(bus *events.Bus) {
+	t.subs = append(t.subs,
+		events.Handle(bus, func(e events.LevelUp) {
+			t.SendNotification("Level Up!", fmt.Sprintf("You reached level %d", e.Level))
+		}),
+		events.Handle(bus, func(e events.PauseChanged) {
+			select {
+			case t.pauseChanged <- struct{}{}:
+			default:
+				// A refresh is already pending
+			}
+			
+			switch {
+			case !e.Paused:
+				t.SendNotification("Shien", "Tracking resumed")
+			case e.Until != nil:
+				t.SendNotification("Shien", "Tracking paused until "+e.Until.Local().Format("15:04"))
+			default:
+				t.SendNotification("Shien", "Tracking paused")
+			}
+		}),
+	)
+}
+
 // Start initializes and runs the system tray
 func (t *Tray) Start() {
 	systray.Run(t.onReady, t.onExit)
@@ -44,6 +100,9 @@ func (t *Tray) Start() {
 
 // Stop gracefully shuts down the system tray
 func (t *Tray) Stop() {
+	for _, sub := range t.subs {
+		sub.Close()
+	}
 	close(t.quit)
 	systray.Quit()
 }
@@ -61,8 +120,16 @@ func (t *Tray) SendNotification(title, message string) {
 		// Drop notification if channel is full
 	}
 	
+	t.mu.RLock()
+	enabled, sound := t.osNotify, t.sound
+	t.mu.RUnlock()
+	if !enabled {
+		return
+	}
+	
 	// Show OS notification using the notification manager
 	t.notifier.SendWithOptions(title, message, notification.Options{
+		Sound: sound,
 		Group: "shien-service",
 	})
 }
@@ -76,6 +143,16 @@ func (t *Tray) onReady() {
 	mStatus := systray.AddMenuItem("Status: Running", "Shien service status")
 	mVersion := systray.AddMenuItem(fmt.Sprintf("Version: %s", version.GetVersion()), "Shien version")
 	mVersion.Disable()
+	
+	// Pause toggle (the state can also change from the CLI or expire, so it is
+	// refreshed on pause events and periodically)
+	var pauseClicked <-chan struct{}
+	var mPause *systray.MenuItem
+	if t.pause != nil {
+		mPause = systray.AddMenuItem(pauseTitle(t.pause.IsPaused()), "Pause or resume activity tracking")
+		pauseClicked = mPause.ClickedCh
+	}
+	refresh := time.NewTicker(10 * time.Second)
 	systray.AddSeparator()
 	
 	// Daily activity menu
@@ -104,8 +181,28 @@ func (t *Tray) onReady() {
 		for {
 			select {
 			case <-t.quit:
+				refresh.Stop()
 				return
 				
+			case <-refresh.C:
+				if mPause != nil {
+					mPause.SetTitle(pauseTitle(t.pause.IsPaused()))
+				}
+				
+			case <-t.pauseChanged:
+				if mPause != nil {
+					mPause.SetTitle(pauseTitle(t.pause.IsPaused()))
+				}
+				
+			case <-pauseClicked:
+				// The notification is sent on the pause event
+				paused, err := t.pause.TogglePause()
+				if err != nil {
+					t.SendNotification("Error", fmt.Sprintf("Failed to change tracking state: %v", err))
+					continue
+				}
+				mPause.SetTitle(pauseTitle(paused))
+				
 			case notification := <-t.notifications:
 				// Add to history
 				notificationHistory = append(notificationHistory, notification)
@@ -183,6 +280,14 @@ func (t *Tray) onReady() {
 	}()
 }
 
+// pauseTitle returns the pause menu item title for the tracking state
+func pauseTitle(paused bool) string {
+	if paused {
+		return "Resume Tracking"
+	}
+	return "Pause Tracking"
+}
+
 func (t *Tray) onExit() {
 	// Cleanup code here
 }
@@ -6,97 +6,183 @@ import (
 	"strings"
 )
 
+// ForegroundWindow describes the window in the foreground.
+// AppName is the raw application name as reported by the OS.
+type ForegroundWindow struct {
+	AppName  string `json:"app_name"`
+	Title    string `json:"title,omitempty"`
+	Document string `json:"document,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
 // GetForegroundApp returns the name of the currently active foreground application
 func GetForegroundApp() (string, error) {
+	window, err := GetForegroundWindow()
+	if err != nil {
+		return "", err
+	}
+
+	// Normalize common app names for consistency
+	return NormalizeAppName(window.AppName), nil
+}
+
+// GetForegroundWindow returns the raw details of the currently active window
+func GetForegroundWindow() (*ForegroundWindow, error) {
 	switch runtime.GOOS {
 	case "darwin":
-		return getForegroundAppMacOS()
+		return getForegroundWindowMacOS()
 	case "windows":
-		return getForegroundAppWindows()
+		return getForegroundWindowWindows()
 	case "linux":
-		return getForegroundAppLinux()
+		return getForegroundWindowLinux()
 	default:
-		return "Unknown", nil
+		return &ForegroundWindow{AppName: "Unknown"}, nil
 	}
 }
 
-// getForegroundAppMacOS gets the foreground app on macOS
-func getForegroundAppMacOS() (string, error) {
-	script := `tell application "System Events" to get name of first application process whose frontmost is true`
+// getForegroundWindowMacOS gets the foreground window on macOS
+func getForegroundWindowMacOS() (*ForegroundWindow, error) {
+	// The window title and AXDocument need accessibility permission,
+	// so failures there still return the app name
+	script := `tell application "System Events"
+	set frontProc to first application process whose frontmost is true
+	set appName to name of frontProc
+	set winTitle to ""
+	set docPath to ""
+	try
+		tell frontProc to set winTitle to name of front window
+	end try
+	try
+		tell frontProc to set docPath to value of attribute "AXDocument" of front window
+	end try
+end tell
+return appName & linefeed & winTitle & linefeed & docPath`
 	cmd := exec.Command("osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	appName := strings.TrimSpace(string(output))
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	window := &ForegroundWindow{AppName: strings.TrimSpace(lines[0])}
+	if len(lines) > 1 {
+		window.Title = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 && lines[2] != "missing value" {
+		window.Document = strings.TrimSpace(lines[2])
+	}
 
-	// Normalize common app names for consistency
-	appName = normalizeAppName(appName)
+	window.URL = getBrowserURLMacOS(window.AppName)
 
-	return appName, nil
+	return window, nil
 }
 
-// getForegroundAppWindows gets the foreground app on Windows
-func getForegroundAppWindows() (string, error) {
+// getBrowserURLMacOS returns the URL of the active tab for scriptable browsers
+func getBrowserURLMacOS(appName string) string {
+	var script string
+	switch appName {
+	case "Safari":
+		script = `tell application "Safari" to get URL of front document`
+	case "Google Chrome", "Microsoft Edge", "Brave Browser", "Arc":
+		script = `tell application "` + appName + `" to get URL of active tab of front window`
+	default:
+		return ""
+	}
+
+	output, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getForegroundWindowWindows gets the foreground window on Windows
+func getForegroundWindowWindows() (*ForegroundWindow, error) {
 	// Windows implementation would use Win32 API
 	// For now, return a placeholder
-	return "Unknown", nil
+	return &ForegroundWindow{AppName: "Unknown"}, nil
 }
 
-// getForegroundAppLinux gets the foreground app on Linux
-func getForegroundAppLinux() (string, error) {
-	// Linux implementation would use X11 or Wayland
-	// For now, return a placeholder
-	return "Unknown", nil
+// linuxDetector detects the foreground window on Linux sessions
+var linuxDetector = NewLinuxDetector(DefaultLinuxBackends()...)
+
+// SetLinuxDetector replaces the detector used on Linux (e.g. with fake backends)
+func SetLinuxDetector(detector *LinuxDetector) {
+	linuxDetector = detector
+}
+
+// getForegroundWindowLinux gets the foreground window on Linux via X11 or Wayland
+func getForegroundWindowLinux() (*ForegroundWindow, error) {
+	return linuxDetector.ForegroundWindow()
 }
 
-// normalizeAppName standardizes application names for consistent tracking
-func normalizeAppName(appName string) string {
-	// Map common variations to standard names
-	nameMap := map[string]string{
-		"Visual Studio Code": "Code Editor",
-		"Code":               "Code Editor",
-		"Cursor":             "Code Editor",
-		"IntelliJ IDEA":      "Code Editor",
-		"Xcode":              "Code Editor",
-		"sublime_text":       "Code Editor",
-		"Terminal":           "Terminal",
-		"iTerm2":             "Terminal",
-		"iTerm":              "Terminal",
-		"kitty":              "Terminal",
-		"Google Chrome":      "Browser",
-		"Safari":             "Browser",
-		"Firefox":            "Browser",
-		"Microsoft Edge":     "Browser",
-		"Arc":                "Browser",
-		"Dia":                "Browser",
-		"Slack":              "Slack",
-		"Microsoft Teams":    "Video Conference",
-		"Zoom":               "Video Conference",
-		"zoom.us":            "Video Conference",
-		"Mail":               "Email",
-		"Outlook":            "Email",
-		"Thunderbird":        "Email",
-		"Figma":              "Design Tool",
-		"Sketch":             "Design Tool",
-		"Adobe Photoshop":    "Design Tool",
-		"Adobe Illustrator":  "Design Tool",
-		"Notion":             "Documentation",
-		"Obsidian":           "Documentation",
-		"Notes":              "Documentation",
-		"Microsoft Word":     "Documentation",
-		"Pages":              "Documentation",
-		"ChatGPT":            "AI Assistant",
-		"Claude":             "AI Assistant",
-		"Discord":            "Communication",
-		"Telegram":           "Communication",
-		"WhatsApp":           "Communication",
-		"Messages":           "Communication",
-		"Chatwork":           "Communication",
+// defaultAppNames maps common app name variations to standard names
+var defaultAppNames = map[string]string{
+	"Visual Studio Code": "Code Editor",
+	"Code":               "Code Editor",
+	"Cursor":             "Code Editor",
+	"IntelliJ IDEA":      "Code Editor",
+	"Xcode":              "Code Editor",
+	"sublime_text":       "Code Editor",
+	"code-oss":           "Code Editor",
+	"jetbrains-idea":     "Code Editor",
+	"Terminal":           "Terminal",
+	"iTerm2":             "Terminal",
+	"iTerm":              "Terminal",
+	"kitty":              "Terminal",
+	"Alacritty":          "Terminal",
+	"Gnome-terminal":     "Terminal",
+	"org.gnome.Terminal": "Terminal",
+	"konsole":            "Terminal",
+	"foot":               "Terminal",
+	"Google Chrome":      "Browser",
+	"Safari":             "Browser",
+	"Firefox":            "Browser",
+	"firefox":            "Browser",
+	"Google-chrome":      "Browser",
+	"Chromium":           "Browser",
+	"Microsoft Edge":     "Browser",
+	"Arc":                "Browser",
+	"Dia":                "Browser",
+	"Slack":              "Slack",
+	"slack":              "Slack",
+	"Microsoft Teams":    "Video Conference",
+	"Zoom":               "Video Conference",
+	"zoom.us":            "Video Conference",
+	"Mail":               "Email",
+	"Outlook":            "Email",
+	"Thunderbird":        "Email",
+	"Figma":              "Design Tool",
+	"Sketch":             "Design Tool",
+	"Adobe Photoshop":    "Design Tool",
+	"Adobe Illustrator":  "Design Tool",
+	"Notion":             "Documentation",
+	"Obsidian":           "Documentation",
+	"Notes":              "Documentation",
+	"Microsoft Word":     "Documentation",
+	"Pages":              "Documentation",
+	"ChatGPT":            "AI Assistant",
+	"Claude":             "AI Assistant",
+	"Discord":            "Communication",
+	"Telegram":           "Communication",
+	"WhatsApp":           "Communication",
+	"Messages":           "Communication",
+	"Chatwork":           "Communication",
+}
+
+// DefaultAppNames returns a copy of the built-in app name mapping
+func DefaultAppNames() map[string]string {
+	names := make(map[string]string, len(defaultAppNames))
+	for raw, category := range defaultAppNames {
+		names[raw] = category
 	}
+	return names
+}
 
-	if normalized, exists := nameMap[appName]; exists {
+// NormalizeAppName standardizes application names for consistent tracking
+// using the built-in mapping
+func NormalizeAppName(appName string) string {
+	if normalized, exists := defaultAppNames[appName]; exists {
 		return normalized
 	}
 
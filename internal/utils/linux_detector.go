@@ -0,0 +1,450 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// LinuxBackend detects the foreground application on one kind of Linux session
+type LinuxBackend interface {
+	// Name identifies the backend in error messages
+	Name() string
+	// Available reports whether the backend applies to the current session
+	Available() bool
+	// ForegroundWindow returns the focused window; AppName holds the raw
+	// application identifier (e.g. WM_CLASS)
+	ForegroundWindow() (*ForegroundWindow, error)
+}
+
+// CommandRunner runs an external command and returns its standard output
+type CommandRunner func(name string, args ...string) ([]byte, error)
+
+// execRunner runs commands with os/exec
+func execRunner(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// LinuxDetector tries each backend in order until one reports an application
+type LinuxDetector struct {
+	backends []LinuxBackend
+}
+
+// NewLinuxDetector creates a detector with the given backends
+func NewLinuxDetector(backends ...LinuxBackend) *LinuxDetector {
+	return &LinuxDetector{backends: backends}
+}
+
+// DefaultLinuxBackends returns the built-in backends for the current session.
+// Wayland compositors are tried first because XWayland only exposes X clients.
+func DefaultLinuxBackends() []LinuxBackend {
+	return []LinuxBackend{
+		NewSwayBackend(os.Getenv, execRunner),
+		NewGnomeBackend(os.Getenv, execRunner),
+		NewKDEBackend(os.Getenv, execRunner),
+		NewX11Backend(os.Getenv, execRunner),
+	}
+}
+
+// ForegroundWindow returns the foreground window reported by the first
+// available backend that succeeds
+func (d *LinuxDetector) ForegroundWindow() (*ForegroundWindow, error) {
+	var failures []string
+	for _, backend := range d.backends {
+		if !backend.Available() {
+			continue
+		}
+
+		window, err := backend.ForegroundWindow()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", backend.Name(), err))
+			continue
+		}
+		if window != nil && window.AppName != "" {
+			return window, nil
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("failed to detect foreground app (%s)", strings.Join(failures, "; "))
+	}
+
+	// No backend applies to this session (e.g. a headless machine)
+	return &ForegroundWindow{AppName: "Unknown"}, nil
+}
+
+// X11Backend reads _NET_ACTIVE_WINDOW, WM_CLASS and _NET_WM_NAME with xprop
+type X11Backend struct {
+	getenv func(string) string
+	run    CommandRunner
+}
+
+// NewX11Backend creates an X11 backend
+func NewX11Backend(getenv func(string) string, run CommandRunner) *X11Backend {
+	return &X11Backend{getenv: getenv, run: run}
+}
+
+// Name returns the backend name
+func (b *X11Backend) Name() string {
+	return "x11"
+}
+
+// Available reports whether an X display is set
+func (b *X11Backend) Available() bool {
+	return b.getenv("DISPLAY") != ""
+}
+
+// ForegroundWindow returns the WM_CLASS class and title of the active window
+func (b *X11Backend) ForegroundWindow() (*ForegroundWindow, error) {
+	windowID, err := b.activeWindow()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := b.run("xprop", "-id", windowID, "WM_CLASS", "_NET_WM_NAME")
+	if err != nil {
+		return nil, err
+	}
+
+	// Output looks like:
+	//   WM_CLASS(STRING) = "gnome-terminal-server", "Gnome-terminal"
+	//   _NET_WM_NAME(UTF8_STRING) = "~/src/shien"
+	window := &ForegroundWindow{}
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "WM_CLASS"):
+			window.AppName, err = parseWMClass(line)
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "_NET_WM_NAME"):
+			window.Title = parseXpropString(line)
+		}
+	}
+
+	if window.AppName == "" {
+		return nil, fmt.Errorf("WM_CLASS not set")
+	}
+	return window, nil
+}
+
+// activeWindow returns the id of the window in _NET_ACTIVE_WINDOW
+func (b *X11Backend) activeWindow() (string, error) {
+	output, err := b.run("xprop", "-root", "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return "", err
+	}
+
+	// Output looks like: _NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected xprop output: %q", output)
+	}
+	windowID := strings.TrimSuffix(fields[len(fields)-1], ",")
+	if !strings.HasPrefix(windowID, "0x") || windowID == "0x0" {
+		return "", fmt.Errorf("no active window")
+	}
+
+	return windowID, nil
+}
+
+// parseWMClass extracts the class (second value) from xprop WM_CLASS output
+func parseWMClass(output string) (string, error) {
+	_, values, found := strings.Cut(output, "=")
+	if !found {
+		return "", fmt.Errorf("WM_CLASS not set")
+	}
+
+	var parts []string
+	for _, part := range strings.Split(values, ",") {
+		part = strings.Trim(strings.TrimSpace(part), `"`)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", fmt.Errorf("WM_CLASS not set")
+	}
+
+	// Prefer the class over the instance name
+	return parts[len(parts)-1], nil
+}
+
+// parseXpropString extracts a quoted string value from an xprop line
+func parseXpropString(line string) string {
+	_, value, found := strings.Cut(line, "=")
+	if !found {
+		return ""
+	}
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return strings.Trim(value, `"`)
+}
+
+// SwayBackend reads the focused node from the sway IPC tree
+type SwayBackend struct {
+	getenv func(string) string
+	run    CommandRunner
+}
+
+// NewSwayBackend creates a sway backend
+func NewSwayBackend(getenv func(string) string, run CommandRunner) *SwayBackend {
+	return &SwayBackend{getenv: getenv, run: run}
+}
+
+// Name returns the backend name
+func (b *SwayBackend) Name() string {
+	return "sway"
+}
+
+// Available reports whether a sway IPC socket is set
+func (b *SwayBackend) Available() bool {
+	return b.getenv("SWAYSOCK") != ""
+}
+
+// swayNode is the subset of a sway tree node we need
+type swayNode struct {
+	Focused          bool       `json:"focused"`
+	Name             string     `json:"name"`
+	AppID            *string    `json:"app_id"`
+	Nodes            []swayNode `json:"nodes"`
+	FloatingNodes    []swayNode `json:"floating_nodes"`
+	WindowProperties *struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+}
+
+// ForegroundWindow returns the app_id (or X11 class) and title of the focused node
+func (b *SwayBackend) ForegroundWindow() (*ForegroundWindow, error) {
+	output, err := b.run("swaymsg", "-t", "get_tree", "-r")
+	if err != nil {
+		return nil, err
+	}
+
+	var root swayNode
+	if err := json.Unmarshal(output, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse sway tree: %w", err)
+	}
+
+	focused := findFocusedSwayNode(&root)
+	if focused == nil {
+		return nil, fmt.Errorf("no focused window")
+	}
+
+	window := &ForegroundWindow{Title: focused.Name}
+	if focused.AppID != nil && *focused.AppID != "" {
+		window.AppName = *focused.AppID
+	} else if focused.WindowProperties != nil && focused.WindowProperties.Class != "" {
+		window.AppName = focused.WindowProperties.Class
+	} else {
+		return nil, fmt.Errorf("focused window has no app_id")
+	}
+
+	return window, nil
+}
+
+// findFocusedSwayNode walks the tree looking for the focused node
+func findFocusedSwayNode(node *swayNode) *swayNode {
+	if node.Focused {
+		return node
+	}
+	for i := range node.Nodes {
+		if found := findFocusedSwayNode(&node.Nodes[i]); found != nil {
+			return found
+		}
+	}
+	for i := range node.FloatingNodes {
+		if found := findFocusedSwayNode(&node.FloatingNodes[i]); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// GnomeBackend asks GNOME Shell over D-Bus for the focused window
+type GnomeBackend struct {
+	getenv func(string) string
+	run    CommandRunner
+}
+
+// NewGnomeBackend creates a GNOME Shell backend
+func NewGnomeBackend(getenv func(string) string, run CommandRunner) *GnomeBackend {
+	return &GnomeBackend{getenv: getenv, run: run}
+}
+
+// Name returns the backend name
+func (b *GnomeBackend) Name() string {
+	return "gnome"
+}
+
+// Available reports whether this is a GNOME Wayland session
+func (b *GnomeBackend) Available() bool {
+	return b.getenv("WAYLAND_DISPLAY") != "" && desktopContains(b.getenv, "GNOME")
+}
+
+// ForegroundWindow returns the WM class and title of the focused window.
+// It uses the "Window Calls" extension when installed and falls back to
+// Shell.Eval, which only works when GNOME Shell runs in unsafe mode.
+func (b *GnomeBackend) ForegroundWindow() (*ForegroundWindow, error) {
+	if window, err := b.fromWindowCalls(); err == nil {
+		return window, nil
+	}
+	return b.fromEval()
+}
+
+// gnomeWindow is a window as described by the GNOME backends
+type gnomeWindow struct {
+	WMClass string `json:"wm_class"`
+	Title   string `json:"title"`
+	Focus   bool   `json:"focus"`
+}
+
+// fromWindowCalls queries the org.gnome.Shell.Extensions.Windows interface
+func (b *GnomeBackend) fromWindowCalls() (*ForegroundWindow, error) {
+	output, err := b.run("gdbus", "call", "--session",
+		"--dest", "org.gnome.Shell",
+		"--object-path", "/org/gnome/Shell/Extensions/Windows",
+		"--method", "org.gnome.Shell.Extensions.Windows.List")
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []gnomeWindow
+	if err := json.Unmarshal([]byte(parseGVariantString(string(output))), &windows); err != nil {
+		return nil, fmt.Errorf("failed to parse window list: %w", err)
+	}
+
+	for _, window := range windows {
+		if window.Focus && window.WMClass != "" {
+			return &ForegroundWindow{AppName: window.WMClass, Title: window.Title}, nil
+		}
+	}
+	return nil, fmt.Errorf("no focused window")
+}
+
+// fromEval evaluates a Shell script describing the focused window
+func (b *GnomeBackend) fromEval() (*ForegroundWindow, error) {
+	output, err := b.run("gdbus", "call", "--session",
+		"--dest", "org.gnome.Shell",
+		"--object-path", "/org/gnome/Shell",
+		"--method", "org.gnome.Shell.Eval",
+		"(w => w ? JSON.stringify({wm_class: w.get_wm_class(), title: w.get_title()}) : '')(global.display.focus_window)")
+	if err != nil {
+		return nil, err
+	}
+
+	// Output looks like: (true, '"{\\"wm_class\\": ...}"')
+	result := strings.TrimSpace(string(output))
+	if strings.HasPrefix(result, "(false") {
+		return nil, fmt.Errorf("Shell.Eval is disabled")
+	}
+
+	// Eval returns a JSON-encoded string holding our JSON object
+	var encoded string
+	if err := json.Unmarshal([]byte(parseGVariantString(result)), &encoded); err != nil {
+		return nil, fmt.Errorf("failed to parse Shell.Eval result: %w", err)
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("no focused window")
+	}
+
+	var window gnomeWindow
+	if err := json.Unmarshal([]byte(encoded), &window); err != nil {
+		return nil, fmt.Errorf("failed to parse Shell.Eval result: %w", err)
+	}
+	return &ForegroundWindow{AppName: window.WMClass, Title: window.Title}, nil
+}
+
+// parseGVariantString extracts the last single-quoted string from gdbus output
+func parseGVariantString(output string) string {
+	start := strings.Index(output, "'")
+	end := strings.LastIndex(output, "'")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return strings.ReplaceAll(output[start+1:end], `\'`, "'")
+}
+
+// KDEBackend asks KWin for the active window via kdotool, which drives
+// KWin's D-Bus scripting interface
+type KDEBackend struct {
+	getenv func(string) string
+	run    CommandRunner
+}
+
+// NewKDEBackend creates a KDE Plasma backend
+func NewKDEBackend(getenv func(string) string, run CommandRunner) *KDEBackend {
+	return &KDEBackend{getenv: getenv, run: run}
+}
+
+// Name returns the backend name
+func (b *KDEBackend) Name() string {
+	return "kde"
+}
+
+// Available reports whether this is a KDE Wayland session
+func (b *KDEBackend) Available() bool {
+	return b.getenv("WAYLAND_DISPLAY") != "" && desktopContains(b.getenv, "KDE")
+}
+
+// ForegroundWindow returns the class name and title of the active window
+func (b *KDEBackend) ForegroundWindow() (*ForegroundWindow, error) {
+	output, err := b.run("kdotool", "getactivewindow", "getwindowclassname", "getwindowname")
+	if err != nil {
+		return nil, err
+	}
+
+	// One line per chained command: class name, then title
+	lines := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)
+	window := &ForegroundWindow{AppName: strings.TrimSpace(lines[0])}
+	if len(lines) > 1 {
+		window.Title = strings.TrimSpace(lines[1])
+	}
+	return window, nil
+}
+
+// desktopContains reports whether XDG_CURRENT_DESKTOP lists the given desktop
+func desktopContains(getenv func(string) string, desktop string) bool {
+	for _, name := range strings.Split(getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if strings.EqualFold(name, desktop) {
+			return true
+		}
+	}
+	return false
+}
+
+// FakeLinuxBackend is a backend with a fixed answer, for tests and headless CI
+type FakeLinuxBackend struct {
+	BackendName string
+	App         string
+	Title       string
+	Err         error
+	Unavailable bool
+}
+
+// Name returns the backend name
+func (b *FakeLinuxBackend) Name() string {
+	if b.BackendName == "" {
+		return "fake"
+	}
+	return b.BackendName
+}
+
+// Available reports whether the fake should be consulted
+func (b *FakeLinuxBackend) Available() bool {
+	return !b.Unavailable
+}
+
+// ForegroundWindow returns the configured window or error
+func (b *FakeLinuxBackend) ForegroundWindow() (*ForegroundWindow, error) {
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	return &ForegroundWindow{AppName: b.App, Title: b.Title}, nil
+}
@@ -0,0 +1,322 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeRunner answers commands by their command line
+func fakeRunner(outputs map[string]string) CommandRunner {
+	return func(name string, args ...string) ([]byte, error) {
+		line := strings.Join(append([]string{name}, args...), " ")
+		for prefix, output := range outputs {
+			if strings.HasPrefix(line, prefix) {
+				return []byte(output), nil
+			}
+		}
+		return nil, fmt.Errorf("unexpected command: %s", line)
+	}
+}
+
+// env returns a getenv reading from a map
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestX11Backend(t *testing.T) {
+	tests := []struct {
+		name      string
+		outputs   map[string]string
+		wantApp   string
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name: "class and title",
+			outputs: map[string]string{
+				"xprop -root":            "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007\n",
+				"xprop -id 0x3a00007 WM": "WM_CLASS(STRING) = \"gnome-terminal-server\", \"Gnome-terminal\"\n_NET_WM_NAME(UTF8_STRING) = \"~/src/shien\"\n",
+			},
+			wantApp:   "Gnome-terminal",
+			wantTitle: "~/src/shien",
+		},
+		{
+			name: "escaped title",
+			outputs: map[string]string{
+				"xprop -root":     "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x1\n",
+				"xprop -id 0x1 W": "WM_CLASS(STRING) = \"firefox\", \"firefox\"\n_NET_WM_NAME(UTF8_STRING) = \"say \\\"hi\\\" — Mozilla Firefox\"\n",
+			},
+			wantApp:   "firefox",
+			wantTitle: `say "hi" — Mozilla Firefox`,
+		},
+		{
+			name: "no active window",
+			outputs: map[string]string{
+				"xprop -root": "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x0\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "WM_CLASS not set",
+			outputs: map[string]string{
+				"xprop -root":     "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x2\n",
+				"xprop -id 0x2 W": "WM_CLASS:  not found.\n_NET_WM_NAME(UTF8_STRING) = \"x\"\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewX11Backend(env(map[string]string{"DISPLAY": ":0"}), fakeRunner(tt.outputs))
+			assertWindow(t, backend, tt.wantApp, tt.wantTitle, tt.wantErr)
+		})
+	}
+}
+
+func TestSwayBackend(t *testing.T) {
+	tests := []struct {
+		name      string
+		tree      string
+		wantApp   string
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name:      "wayland app_id",
+			tree:      `{"nodes":[{"nodes":[{"focused":true,"name":"vim","app_id":"foot"}]}]}`,
+			wantApp:   "foot",
+			wantTitle: "vim",
+		},
+		{
+			name:      "xwayland class",
+			tree:      `{"nodes":[{"nodes":[{"focused":false,"app_id":"foot"},{"focused":true,"name":"Slack","app_id":null,"window_properties":{"class":"Slack"}}]}]}`,
+			wantApp:   "Slack",
+			wantTitle: "Slack",
+		},
+		{
+			name:      "floating window",
+			tree:      `{"nodes":[{"floating_nodes":[{"focused":true,"name":"Calculator","app_id":"org.gnome.Calculator"}]}]}`,
+			wantApp:   "org.gnome.Calculator",
+			wantTitle: "Calculator",
+		},
+		{
+			name:    "workspace focused",
+			tree:    `{"nodes":[{"focused":true,"name":"1"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "nothing focused",
+			tree:    `{"nodes":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			tree:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewSwayBackend(env(map[string]string{"SWAYSOCK": "/run/sway.sock"}), fakeRunner(map[string]string{
+				"swaymsg -t get_tree": tt.tree,
+			}))
+			assertWindow(t, backend, tt.wantApp, tt.wantTitle, tt.wantErr)
+		})
+	}
+}
+
+func TestGnomeBackend(t *testing.T) {
+	const windowCalls = "gdbus call --session --dest org.gnome.Shell --object-path /org/gnome/Shell/Extensions/Windows"
+	const eval = "gdbus call --session --dest org.gnome.Shell --object-path /org/gnome/Shell --method"
+
+	tests := []struct {
+		name      string
+		outputs   map[string]string
+		wantApp   string
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name: "window calls extension",
+			outputs: map[string]string{
+				windowCalls: `('[{"wm_class":"Code","title":"main.go","focus":false},{"wm_class":"org.gnome.Nautilus","title":"It\'s home","focus":true}]',)`,
+			},
+			wantApp:   "org.gnome.Nautilus",
+			wantTitle: "It's home",
+		},
+		{
+			name: "falls back to Shell.Eval",
+			outputs: map[string]string{
+				eval: `(true, '"{\"wm_class\":\"firefox\",\"title\":\"Docs\"}"')`,
+			},
+			wantApp:   "firefox",
+			wantTitle: "Docs",
+		},
+		{
+			name: "Shell.Eval disabled",
+			outputs: map[string]string{
+				eval: `(false, '')`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "no focused window",
+			outputs: map[string]string{
+				windowCalls: `('[{"wm_class":"Code","title":"main.go","focus":false}]',)`,
+				eval:        `(true, '""')`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewGnomeBackend(env(map[string]string{
+				"WAYLAND_DISPLAY":     "wayland-0",
+				"XDG_CURRENT_DESKTOP": "ubuntu:GNOME",
+			}), fakeRunner(tt.outputs))
+			assertWindow(t, backend, tt.wantApp, tt.wantTitle, tt.wantErr)
+		})
+	}
+}
+
+func TestKDEBackend(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		wantApp   string
+		wantTitle string
+	}{
+		{
+			name:      "class and title",
+			output:    "org.kde.konsole\n~ : bash — Konsole\n",
+			wantApp:   "org.kde.konsole",
+			wantTitle: "~ : bash — Konsole",
+		},
+		{
+			name:    "class only",
+			output:  "org.kde.dolphin\n",
+			wantApp: "org.kde.dolphin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewKDEBackend(env(map[string]string{
+				"WAYLAND_DISPLAY":     "wayland-0",
+				"XDG_CURRENT_DESKTOP": "KDE",
+			}), fakeRunner(map[string]string{"kdotool": tt.output}))
+			assertWindow(t, backend, tt.wantApp, tt.wantTitle, false)
+		})
+	}
+}
+
+func TestBackendAvailability(t *testing.T) {
+	run := fakeRunner(nil)
+	tests := []struct {
+		name    string
+		backend LinuxBackend
+		want    bool
+	}{
+		{"x11 with display", NewX11Backend(env(map[string]string{"DISPLAY": ":0"}), run), true},
+		{"x11 headless", NewX11Backend(env(nil), run), false},
+		{"sway with socket", NewSwayBackend(env(map[string]string{"SWAYSOCK": "/run/sway.sock"}), run), true},
+		{"gnome on x11", NewGnomeBackend(env(map[string]string{"XDG_CURRENT_DESKTOP": "GNOME"}), run), false},
+		{"gnome on wayland", NewGnomeBackend(env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "XDG_CURRENT_DESKTOP": "ubuntu:GNOME"}), run), true},
+		{"kde on gnome", NewKDEBackend(env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "XDG_CURRENT_DESKTOP": "GNOME"}), run), false},
+		{"kde on wayland", NewKDEBackend(env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "XDG_CURRENT_DESKTOP": "kde"}), run), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.backend.Available(); got != tt.want {
+				t.Errorf("Available() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinuxDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends []LinuxBackend
+		wantApp  string
+		wantErr  bool
+	}{
+		{
+			name: "first available backend wins",
+			backends: []LinuxBackend{
+				&FakeLinuxBackend{BackendName: "sway", App: "foot", Unavailable: true},
+				&FakeLinuxBackend{BackendName: "gnome", App: "firefox"},
+				&FakeLinuxBackend{BackendName: "x11", App: "xterm"},
+			},
+			wantApp: "firefox",
+		},
+		{
+			name: "falls back after a failure",
+			backends: []LinuxBackend{
+				&FakeLinuxBackend{BackendName: "gnome", Err: errors.New("Shell.Eval is disabled")},
+				&FakeLinuxBackend{BackendName: "x11", App: "xterm"},
+			},
+			wantApp: "xterm",
+		},
+		{
+			name: "every backend fails",
+			backends: []LinuxBackend{
+				&FakeLinuxBackend{BackendName: "gnome", Err: errors.New("Shell.Eval is disabled")},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "headless",
+			backends: []LinuxBackend{&FakeLinuxBackend{Unavailable: true}},
+			wantApp:  "Unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := NewLinuxDetector(tt.backends...).ForegroundWindow()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ForegroundWindow() = %+v, want an error", window)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForegroundWindow() error = %v", err)
+			}
+			if window.AppName != tt.wantApp {
+				t.Errorf("AppName = %q, want %q", window.AppName, tt.wantApp)
+			}
+		})
+	}
+}
+
+// assertWindow checks the window a backend reports
+func assertWindow(t *testing.T, backend LinuxBackend, wantApp, wantTitle string, wantErr bool) {
+	t.Helper()
+
+	window, err := backend.ForegroundWindow()
+	if wantErr {
+		if err == nil {
+			t.Fatalf("ForegroundWindow() = %+v, want an error", window)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("ForegroundWindow() error = %v", err)
+	}
+	if window.AppName != wantApp {
+		t.Errorf("AppName = %q, want %q", window.AppName, wantApp)
+	}
+	if window.Title != wantTitle {
+		t.Errorf("Title = %q, want %q", window.Title, wantTitle)
+	}
+}